@@ -0,0 +1,174 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package ast declares types representing a parsed grok expression
+// and a parser shared by go-grok and tooling built on top of it.
+//
+// A grok expression is an RE2 regular expression which may contain references
+// to other patterns in one of these forms:
+//
+//	%{SYNTAX} - e.g %{NUMBER}
+//	%{SYNTAX:ID} - e.g %{NUMBER:MY_AGE}
+//	%{SYNTAX:ID:TYPE} - e.g %{NUMBER:MY_AGE:int}
+//
+// Parsed expression is a tree of literal regex fragments, references, character classes,
+// alternation operators and groups. Every node knows its byte offsets within the source,
+// concatenating String() of all nodes gives back the original expression.
+package ast
+
+import (
+	"strings"
+)
+
+// Node is implemented by all nodes of the tree.
+type Node interface {
+	// Pos returns offset of the first byte of the node within the source.
+	Pos() int
+	// End returns offset of the first byte after the node.
+	End() int
+	// String returns source text of the node.
+	String() string
+}
+
+// Expression is the root of a parsed grok expression.
+type Expression struct {
+	Source   string
+	Children []Node
+}
+
+// Literal is a regex fragment without any groups, character classes, references or alternations,
+// e.g `\s+` or `foo\(bar`.
+// Text which looks like a reference but does not follow reference syntax is kept as literal.
+type Literal struct {
+	Offset int
+	Value  string
+}
+
+// Reference is a reference to another pattern, e.g %{NUMBER:size:int}.
+type Reference struct {
+	Offset int
+	// Raw is the source text of the reference.
+	Raw string
+	// Syntax is the name of referenced pattern.
+	Syntax string
+	// ID is the name of the field capturing referenced pattern, empty when not captured.
+	ID string
+	// Type is the type hint of the captured field, empty when not provided.
+	Type string
+}
+
+// CharClass is a bracketed character class, e.g [^a-z\]].
+type CharClass struct {
+	Offset int
+	Value  string
+}
+
+// Alternation is a '|' operator separating alternatives of the enclosing group or expression.
+type Alternation struct {
+	Offset int
+}
+
+// GroupKind distinguishes kinds of groups.
+type GroupKind int
+
+const (
+	// Capturing is an unnamed capturing group, e.g (a).
+	Capturing GroupKind = iota
+	// NamedCapturing is a named capturing group, e.g (?P<name>a) or (?<name>a).
+	NamedCapturing
+	// NonCapturing is a non-capturing group, possibly with flags, e.g (?:a) or (?i:a).
+	NonCapturing
+)
+
+// Group is a parenthesized group.
+type Group struct {
+	Offset int
+	// EndOffset is the offset of the first byte after closing parenthesis.
+	EndOffset int
+	Kind      GroupKind
+	// Open is the source text of the group opening, e.g "(", "(?:" or "(?P<name>".
+	Open string
+	// Name is the name of NamedCapturing group.
+	Name string
+	// Flags are flags of NonCapturing group, e.g "i" for (?i:a).
+	Flags    string
+	Children []Node
+}
+
+func (e *Expression) Pos() int       { return 0 }
+func (e *Expression) End() int       { return len(e.Source) }
+func (e *Expression) String() string { return e.Source }
+
+func (l *Literal) Pos() int       { return l.Offset }
+func (l *Literal) End() int       { return l.Offset + len(l.Value) }
+func (l *Literal) String() string { return l.Value }
+
+func (r *Reference) Pos() int       { return r.Offset }
+func (r *Reference) End() int       { return r.Offset + len(r.Raw) }
+func (r *Reference) String() string { return r.Raw }
+
+func (c *CharClass) Pos() int       { return c.Offset }
+func (c *CharClass) End() int       { return c.Offset + len(c.Value) }
+func (c *CharClass) String() string { return c.Value }
+
+func (a *Alternation) Pos() int       { return a.Offset }
+func (a *Alternation) End() int       { return a.Offset + 1 }
+func (a *Alternation) String() string { return "|" }
+
+func (g *Group) Pos() int { return g.Offset }
+func (g *Group) End() int { return g.EndOffset }
+func (g *Group) String() string {
+	var sb strings.Builder
+	sb.WriteString(g.Open)
+	for _, child := range g.Children {
+		sb.WriteString(child.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Inspect traverses the tree in depth-first order starting with node.
+// Children of a node are visited only when f returns true.
+func Inspect(node Node, f func(Node) bool) {
+	if !f(node) {
+		return
+	}
+
+	var children []Node
+	switch n := node.(type) {
+	case *Expression:
+		children = n.Children
+	case *Group:
+		children = n.Children
+	}
+
+	for _, child := range children {
+		Inspect(child, f)
+	}
+}
+
+// References returns all references of the tree in order of appearance.
+func References(node Node) []*Reference {
+	var references []*Reference
+	Inspect(node, func(n Node) bool {
+		if r, ok := n.(*Reference); ok {
+			references = append(references, r)
+		}
+		return true
+	})
+	return references
+}
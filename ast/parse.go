@@ -0,0 +1,262 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrSyntax is wrapped by all errors returned by Parse.
+var ErrSyntax = errors.New("invalid grok expression")
+
+// Error describes a syntax error at a given offset of the source.
+type Error struct {
+	Offset  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %s at offset %d", ErrSyntax, e.Message, e.Offset)
+}
+
+func (e *Error) Unwrap() error {
+	return ErrSyntax
+}
+
+// reference grammar, see package documentation
+var referencePattern = regexp.MustCompile(`^%{(\w+)(?::([\w+.]+)(?::(\w+))?)?}`)
+
+// named ASCII class within a character class, e.g [[:alpha:]]
+var posixClassPattern = regexp.MustCompile(`^\[:\^?[a-z]+:\]`)
+
+// Parse parses grok expression into a tree.
+// Only the structure relevant to grok is validated: balanced groups, terminated character classes and escapes.
+// Remaining validation is left to regexp.Compile of the expanded expression.
+func Parse(expression string) (*Expression, error) {
+	p := parser{src: expression}
+
+	children, err := p.parseSequence(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, &Error{Offset: p.pos, Message: "unexpected )"}
+	}
+
+	return &Expression{Source: expression, Children: children}, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+// parseSequence parses nodes until the end of source or a closing parenthesis of enclosing group.
+func (p *parser) parseSequence(depth int) ([]Node, error) {
+	var nodes []Node
+	literalStart := p.pos
+
+	flushLiteral := func() {
+		if p.pos > literalStart {
+			nodes = append(nodes, &Literal{Offset: literalStart, Value: p.src[literalStart:p.pos]})
+		}
+	}
+
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+
+		if strings.HasPrefix(rest, "%{") {
+			if m := referencePattern.FindStringSubmatch(rest); m != nil {
+				flushLiteral()
+				nodes = append(nodes, &Reference{Offset: p.pos, Raw: m[0], Syntax: m[1], ID: m[2], Type: m[3]})
+				p.pos += len(m[0])
+				literalStart = p.pos
+				continue
+			}
+		}
+
+		switch rest[0] {
+		case '\\':
+			end, err := p.escapeEnd()
+			if err != nil {
+				return nil, err
+			}
+			p.pos = end
+
+		case '[':
+			flushLiteral()
+			end, err := p.classEnd()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, &CharClass{Offset: p.pos, Value: p.src[p.pos:end]})
+			p.pos = end
+			literalStart = p.pos
+
+		case '|':
+			flushLiteral()
+			nodes = append(nodes, &Alternation{Offset: p.pos})
+			p.pos++
+			literalStart = p.pos
+
+		case '(':
+			if flags, ok := flagsOnly(rest); ok {
+				// (?i) changes flags of the current group and is kept as literal
+				p.pos += len(flags)
+				continue
+			}
+
+			flushLiteral()
+			group, err := p.parseGroup(depth)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, group)
+			literalStart = p.pos
+
+		case ')':
+			if depth == 0 {
+				return nil, &Error{Offset: p.pos, Message: "unexpected )"}
+			}
+			flushLiteral()
+			return nodes, nil
+
+		default:
+			p.pos++
+		}
+	}
+
+	if depth > 0 {
+		return nil, &Error{Offset: p.pos, Message: "missing closing )"}
+	}
+
+	flushLiteral()
+	return nodes, nil
+}
+
+func (p *parser) parseGroup(depth int) (*Group, error) {
+	start := p.pos
+	rest := p.src[p.pos:]
+	group := &Group{Offset: start}
+
+	switch {
+	case strings.HasPrefix(rest, "(?P<"), strings.HasPrefix(rest, "(?<"):
+		nameStart := strings.IndexByte(rest, '<') + 1
+		nameEnd := strings.IndexByte(rest, '>')
+		if nameEnd < nameStart {
+			return nil, &Error{Offset: start, Message: "missing closing > of group name"}
+		}
+		group.Kind = NamedCapturing
+		group.Name = rest[nameStart:nameEnd]
+		group.Open = rest[:nameEnd+1]
+
+	case strings.HasPrefix(rest, "(?"):
+		colon := strings.IndexByte(rest, ':')
+		if colon < 0 || !isFlags(rest[2:colon]) {
+			return nil, &Error{Offset: start, Message: "unsupported group"}
+		}
+		group.Kind = NonCapturing
+		group.Flags = rest[2:colon]
+		group.Open = rest[:colon+1]
+
+	default:
+		group.Kind = Capturing
+		group.Open = "("
+	}
+
+	p.pos += len(group.Open)
+	children, err := p.parseSequence(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// parseSequence stops at the closing parenthesis
+	p.pos++
+	group.Children = children
+	group.EndOffset = p.pos
+
+	return group, nil
+}
+
+// escapeEnd returns offset right after the escape sequence starting at current position.
+func (p *parser) escapeEnd() (int, error) {
+	if p.pos+1 >= len(p.src) {
+		return 0, &Error{Offset: p.pos, Message: "trailing \\"}
+	}
+
+	if p.src[p.pos+1] == 'Q' {
+		// \Q...\E quotes everything in between
+		if end := strings.Index(p.src[p.pos+2:], `\E`); end >= 0 {
+			return p.pos + 2 + end + 2, nil
+		}
+		return len(p.src), nil
+	}
+
+	return p.pos + 2, nil
+}
+
+// classEnd returns offset right after the character class starting at current position.
+func (p *parser) classEnd() (int, error) {
+	i := p.pos + 1
+	if i < len(p.src) && p.src[i] == '^' {
+		i++
+	}
+	if i < len(p.src) && p.src[i] == ']' {
+		// leading ] is matched literally
+		i++
+	}
+
+	for i < len(p.src) {
+		if posix := posixClassPattern.FindString(p.src[i:]); posix != "" {
+			i += len(posix)
+			continue
+		}
+
+		switch p.src[i] {
+		case '\\':
+			i += 2
+			continue
+		case ']':
+			return i + 1, nil
+		}
+		i++
+	}
+
+	return 0, &Error{Offset: p.pos, Message: "missing closing ]"}
+}
+
+// flagsOnly returns flags group without body, e.g (?i), when rest starts with one.
+func flagsOnly(rest string) (string, bool) {
+	if !strings.HasPrefix(rest, "(?") {
+		return "", false
+	}
+
+	end := strings.IndexByte(rest, ')')
+	if end < 0 || !isFlags(rest[2:end]) {
+		return "", false
+	}
+
+	return rest[:end+1], true
+}
+
+func isFlags(s string) bool {
+	return strings.Trim(s, "imsU-") == ""
+}
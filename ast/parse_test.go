@@ -0,0 +1,156 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok/ast"
+	"github.com/elastic/go-grok/patterns"
+)
+
+func TestParse(t *testing.T) {
+	expression, err := ast.Parse(`^%{IP:source.ip} (?:[^\]]+|-) (?P<user>%{USER:user.name:string})%{DATA:user.name?}$`)
+	require.NoError(t, err)
+
+	require.Equal(t, []ast.Node{
+		&ast.Literal{Offset: 0, Value: "^"},
+		&ast.Reference{Offset: 1, Raw: "%{IP:source.ip}", Syntax: "IP", ID: "source.ip"},
+		&ast.Literal{Offset: 16, Value: " "},
+		&ast.Group{
+			Offset:    17,
+			EndOffset: 29,
+			Kind:      ast.NonCapturing,
+			Open:      "(?:",
+			Children: []ast.Node{
+				&ast.CharClass{Offset: 20, Value: `[^\]]`},
+				&ast.Literal{Offset: 25, Value: "+"},
+				&ast.Alternation{Offset: 26},
+				&ast.Literal{Offset: 27, Value: "-"},
+			},
+		},
+		&ast.Literal{Offset: 29, Value: " "},
+		&ast.Group{
+			Offset:    30,
+			EndOffset: 64,
+			Kind:      ast.NamedCapturing,
+			Open:      "(?P<user>",
+			Name:      "user",
+			Children: []ast.Node{
+				&ast.Reference{Offset: 39, Raw: "%{USER:user.name:string}", Syntax: "USER", ID: "user.name", Type: "string"},
+			},
+		},
+		&ast.Literal{Offset: 64, Value: "%{DATA:user.name?}$"},
+	}, expression.Children)
+}
+
+func TestParse_Groups(t *testing.T) {
+	testCases := []struct {
+		Expression string
+		Kind       ast.GroupKind
+		Name       string
+		Flags      string
+	}{
+		{`(a)`, ast.Capturing, "", ""},
+		{`(?:a)`, ast.NonCapturing, "", ""},
+		{`(?i:a)`, ast.NonCapturing, "", "i"},
+		{`(?P<n>a)`, ast.NamedCapturing, "n", ""},
+		{`(?<n>a)`, ast.NamedCapturing, "n", ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Expression, func(t *testing.T) {
+			expression, err := ast.Parse(tt.Expression)
+			require.NoError(t, err)
+			require.Len(t, expression.Children, 1)
+
+			group, ok := expression.Children[0].(*ast.Group)
+			require.True(t, ok)
+			require.Equal(t, tt.Kind, group.Kind)
+			require.Equal(t, tt.Name, group.Name)
+			require.Equal(t, tt.Flags, group.Flags)
+			require.Equal(t, tt.Expression, group.String())
+		})
+	}
+}
+
+func TestParse_Literals(t *testing.T) {
+	testCases := []string{
+		`\(a\)`,
+		`(?i)abc`,
+		`\Q(|)\E`,
+		`\[%{`,
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt, func(t *testing.T) {
+			expression, err := ast.Parse(tt)
+			require.NoError(t, err)
+			require.Equal(t, []ast.Node{&ast.Literal{Value: tt}}, expression.Children)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		Expression string
+		Offset     int
+	}{
+		{`(a`, 2},
+		{`a)`, 1},
+		{`[a-z`, 0},
+		{`[[:alpha:]`, 0},
+		{`a\`, 1},
+		{`(?=a)`, 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Expression, func(t *testing.T) {
+			_, err := ast.Parse(tt.Expression)
+			require.ErrorIs(t, err, ast.ErrSyntax)
+
+			var syntaxErr *ast.Error
+			require.ErrorAs(t, err, &syntaxErr)
+			require.Equal(t, tt.Offset, syntaxErr.Offset)
+		})
+	}
+}
+
+func TestParse_BundledPatterns(t *testing.T) {
+	for _, collection := range []patterns.Collection{patterns.ECSv1, patterns.Legacy} {
+		for _, set := range collection.All() {
+			for name, definition := range set {
+				expression, err := ast.Parse(definition)
+				require.NoError(t, err, name)
+
+				var sb strings.Builder
+				for _, node := range expression.Children {
+					sb.WriteString(node.String())
+				}
+				require.Equal(t, definition, sb.String(), name)
+
+				for _, reference := range ast.References(expression) {
+					require.Equal(t, reference.Raw, definition[reference.Pos():reference.End()], name)
+				}
+			}
+		}
+	}
+}
@@ -18,7 +18,10 @@
 package benchmarks_test
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,6 +29,7 @@ import (
 	vgrok "github.com/vjeantet/grok"
 
 	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/patterns"
 )
 
 // Comparison benchmarks
@@ -191,3 +195,239 @@ func BenchmarkTypedParseStringVjeanet(b *testing.B) {
 		require.NoError(b, e)
 	}
 }
+
+// Capture groups benchmarks
+
+const combinedLogLine = `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`
+
+// BenchmarkSubmatchCaptureGroups compares submatching with only named fields captured
+// to capturing every group of bundled definitions as well.
+func BenchmarkSubmatchCaptureGroups(b *testing.B) {
+	g, err := grok.NewComplete()
+	require.NoError(b, err)
+
+	for _, bc := range []struct {
+		name              string
+		namedCapturesOnly bool
+	}{
+		{"named-only", true},
+		{"all", false},
+	} {
+		expansion, err := g.Expand("%{HTTPD_COMBINEDLOG}", bc.namedCapturesOnly)
+		require.NoError(b, err)
+		re := regexp.MustCompile(expansion.Regex)
+
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportMetric(float64(expansion.Captures), "captures")
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				re.FindStringSubmatchIndex(combinedLogLine)
+			}
+		})
+	}
+}
+
+func BenchmarkParseStringCombinedLog(b *testing.B) {
+	g, err := grok.NewComplete()
+	require.NoError(b, err)
+	require.NoError(b, g.Compile("%{HTTPD_COMBINEDLOG}", true))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		g.ParseString(combinedLogLine)
+	}
+}
+
+// Prefilter benchmarks
+
+// BenchmarkParseStringNonMatching parses lines lacking literals required by the expression,
+// they are rejected before running the regular expression, and lines not matching an expression
+// without literals with and without matching them first.
+func BenchmarkParseStringNonMatching(b *testing.B) {
+	g, err := grok.NewComplete()
+	require.NoError(b, err)
+	require.NoError(b, g.Compile("%{CISCOFW106001}", true))
+
+	expansion, err := g.Expand("%{CISCOFW106001}", true)
+	require.NoError(b, err)
+	re := regexp.MustCompile(expansion.Regex)
+
+	b.Run("prefiltered", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			g.ParseString(combinedLogLine)
+		}
+	})
+
+	b.Run("regexp", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			re.FindStringSubmatch(combinedLogLine)
+		}
+	})
+
+	// expression requiring no literals is rejected by matching when compiled with WithMatchFirst
+	submatched, err := grok.NewComplete()
+	require.NoError(b, err)
+	require.NoError(b, submatched.Compile("%{CISCOFW106006_106007_106010}", true))
+	matchedFirst, err := grok.NewComplete()
+	require.NoError(b, err)
+	require.NoError(b, matchedFirst.Compile("%{CISCOFW106006_106007_106010}", true, grok.WithMatchFirst()))
+
+	b.Run("no literals/submatch", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			submatched.ParseString(combinedLogLine)
+		}
+	})
+
+	b.Run("no literals/match first", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			matchedFirst.ParseString(combinedLogLine)
+		}
+	})
+}
+
+// Multi-pattern benchmarks
+
+// BenchmarkMatcherManyExpressions parses lines matched by the last of many expressions distinguished by literals,
+// literals of all expressions are looked up in a single pass by a matcher instead of for every expression.
+func BenchmarkMatcherManyExpressions(b *testing.B) {
+	g, err := grok.NewComplete()
+	require.NoError(b, err)
+
+	expressions := make([]string, 200)
+	for i := range expressions {
+		expressions[i] = fmt.Sprintf(`%%{TIMESTAMP_ISO8601:timestamp} service=billing-%d action=%%{WORD:event.action} user=%%{USERNAME:user.name} took=%%{INT:event.duration:int}ms`, i)
+	}
+
+	m, err := g.CompileMatcher(expressions, true)
+	require.NoError(b, err)
+
+	sequential := make([]*grok.Grok, len(expressions))
+	for i, expression := range expressions {
+		sequential[i], err = grok.NewComplete()
+		require.NoError(b, err)
+		require.NoError(b, sequential[i].Compile(expression, true))
+	}
+
+	line := fmt.Sprintf("2024-05-01T12:00:00Z service=billing-%d action=charge user=alice took=12ms", len(expressions)-1)
+	index, _, err := m.ParseString(line)
+	require.NoError(b, err)
+	require.Equal(b, len(expressions)-1, index)
+
+	b.Run("matcher", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			m.ParseString(line)
+		}
+	})
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, s := range sequential {
+				if s.MatchString(line) {
+					s.ParseString(line)
+					break
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkFirewalls parses a line matching none of CISCOFW patterns and a line matching the last of them
+// with a matcher and with expressions tried one after the other.
+func BenchmarkFirewalls(b *testing.B) {
+	g, err := grok.NewComplete()
+	require.NoError(b, err)
+
+	var expressions []string
+	for name := range patterns.Firewalls {
+		if strings.HasPrefix(name, "CISCOFW") {
+			expressions = append(expressions, "%{"+name+"}")
+		}
+	}
+	sort.Strings(expressions)
+
+	m, err := g.CompileMatcher(expressions, true)
+	require.NoError(b, err)
+
+	sequential := make([]*grok.Grok, len(expressions))
+	for i, expression := range expressions {
+		sequential[i], err = grok.NewComplete()
+		require.NoError(b, err)
+		require.NoError(b, sequential[i].Compile(expression, true))
+	}
+
+	lines := map[string]string{
+		"unmatched": combinedLogLine,
+		"matched":   "Deny tcp src outside:10.1.1.1/1234 dst inside:10.2.2.2/80 by access-group \"acl_outside\" [0x0, 0x0]",
+	}
+	for _, name := range []string{"unmatched", "matched"} {
+		line := lines[name]
+
+		b.Run(name+"/matcher", func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				m.ParseString(line)
+			}
+		})
+
+		b.Run(name+"/sequential", func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				for _, s := range sequential {
+					if s.MatchString(line) {
+						s.ParseString(line)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMatcherAdaptiveOrder parses lines matched by the last of anchored expressions sharing their literals
+// with a matcher trying them in declared order and with a matcher trying them in adaptive order.
+func BenchmarkMatcherAdaptiveOrder(b *testing.B) {
+	g, err := grok.NewComplete()
+	require.NoError(b, err)
+
+	// event codes given in short or long form are not required literals
+	expressions := make([]string, 50)
+	for i := range expressions {
+		expressions[i] = fmt.Sprintf(`^%%{TIMESTAMP_ISO8601:timestamp} (?:E%03d|ERR%03d) %%{WORD:service.name} %%{GREEDYDATA:message}$`, i, i)
+	}
+
+	m, err := g.CompileMatcher(expressions, true)
+	require.NoError(b, err)
+	adaptive, err := g.CompileMatcher(expressions, true)
+	require.NoError(b, err)
+	adaptive.EnableAdaptiveOrder(1000)
+
+	line := fmt.Sprintf("2024-05-01T12:00:00Z E%03d billing charge failed for user alice", len(expressions)-1)
+	require.Len(b, m.Candidates(line), len(expressions))
+	for n := 0; n < 1000; n++ {
+		index, _, err := adaptive.ParseString(line)
+		require.NoError(b, err)
+		require.Equal(b, len(expressions)-1, index)
+	}
+	require.Equal(b, len(expressions)-1, adaptive.Stats()[0].Index)
+
+	b.Run("matcher", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			m.ParseString(line)
+		}
+	})
+
+	b.Run("adaptive", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			adaptive.ParseString(line)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+)
+
+// MatchDetails describes a match of an expression, scoring policies rank matches by it.
+type MatchDetails struct {
+	// Index is the index of expression as given to CompileMatcher.
+	Index int
+	// Fields is the number of fields captured with a non-empty value.
+	Fields int
+	// LiteralLength is the number of bytes of literal text required by the expression.
+	LiteralLength int
+	// WildcardLength is the number of bytes consumed by wildcards matching any text, e.g. GREEDYDATA and DATA.
+	WildcardLength int
+}
+
+// ScoringPolicy scores a match, matches with higher scores are better.
+type ScoringPolicy func(d MatchDetails) int
+
+// MostFields prefers matches capturing the most fields.
+func MostFields(d MatchDetails) int {
+	return d.Fields
+}
+
+// LongestLiterals prefers expressions with the most literal text, i.e. the most specific ones.
+func LongestLiterals(d MatchDetails) int {
+	return d.LiteralLength
+}
+
+// LeastWildcard prefers matches leaving the least text to wildcards such as GREEDYDATA.
+func LeastWildcard(d MatchDetails) int {
+	return -d.WildcardLength
+}
+
+// ScoredMatch is a match of an expression scored by a policy.
+type ScoredMatch struct {
+	MatchDetails
+	// Score is the score given by the policy.
+	Score int
+	// Values are captured values not converted to types according to hints.
+	Values map[string]string
+}
+
+// BestMatchOption configures ParseBestString.
+type BestMatchOption func(*bestMatchConfig)
+
+type bestMatchConfig struct {
+	runnersUp int
+}
+
+// WithRunnersUp returns up to n matches following the best one.
+func WithRunnersUp(n int) BestMatchOption {
+	return func(cfg *bestMatchConfig) {
+		cfg.runnersUp = n
+	}
+}
+
+// scoringExpression is an expression of a matcher prepared for scoring.
+type scoringExpression struct {
+	// re is the expression with wildcards captured by unnamed groups
+	re *regexp.Regexp
+	// wildcards are indexes of groups capturing wildcards
+	wildcards []int
+	// literalLength is the number of bytes of literal text required by the expression
+	literalLength int
+}
+
+// ParseBestString parses text with every matching expression instead of the first one and returns
+// the match scored best by policy followed by runners-up when requested, best first.
+// Matches scored equally are ordered by priority of expressions. MostFields is used when policy is nil.
+// When no expression matches nil is returned.
+func (m *Matcher) ParseBestString(text string, policy ScoringPolicy, opts ...BestMatchOption) ([]ScoredMatch, error) {
+	var cfg bestMatchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if policy == nil {
+		policy = MostFields
+	}
+
+	m.scoringOnce.Do(m.prepareScoring)
+
+	s := m.scratch.Get().(*matcherScratch)
+	defer m.scratch.Put(s)
+
+	var matches []ScoredMatch
+	for _, i := range m.candidates(text, s) {
+		scoring := m.scoring[i]
+		loc := scoring.re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			continue
+		}
+
+		submatches := make([]string, len(loc)/2)
+		for k := range submatches {
+			if loc[2*k] >= 0 {
+				submatches[k] = text[loc[2*k]:loc[2*k+1]]
+			}
+		}
+		values, err := capturesOf(scoring.re, submatches, func(v, _ string) (string, error) {
+			return v, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		details := MatchDetails{
+			Index:         i,
+			Fields:        len(values),
+			LiteralLength: scoring.literalLength,
+		}
+		for _, k := range scoring.wildcards {
+			if loc[2*k] >= 0 {
+				details.WildcardLength += loc[2*k+1] - loc[2*k]
+			}
+		}
+
+		matches = append(matches, ScoredMatch{
+			MatchDetails: details,
+			Score:        policy(details),
+			Values:       values,
+		})
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	// candidates are in order of priority
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if limit := 1 + cfg.runnersUp; len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	m.matches[matches[0].Index].Add(1)
+	return matches, nil
+}
+
+// prepareScoring prepares expressions for scoring on first use of ParseBestString.
+func (m *Matcher) prepareScoring() {
+	m.scoring = make([]scoringExpression, len(m.expressions))
+	for i, g := range m.expressions {
+		m.scoring[i] = newScoringExpression(g.re)
+	}
+}
+
+func newScoringExpression(re *regexp.Regexp) scoringExpression {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		// re is already compiled, wildcards are not measured when it can not be parsed again
+		return scoringExpression{re: re}
+	}
+
+	s := scoringExpression{literalLength: literalLength(parsed)}
+
+	// capture groups are numbered by their opening parentheses, i.e. in preorder
+	groups := 0
+	var captureWildcards func(re *syntax.Regexp) *syntax.Regexp
+	captureWildcards = func(re *syntax.Regexp) *syntax.Regexp {
+		if isWildcard(re) {
+			groups++
+			s.wildcards = append(s.wildcards, groups)
+			return &syntax.Regexp{Op: syntax.OpCapture, Flags: re.Flags, Sub: []*syntax.Regexp{re}}
+		}
+		if re.Op == syntax.OpCapture {
+			groups++
+		}
+		for k, sub := range re.Sub {
+			re.Sub[k] = captureWildcards(sub)
+		}
+		return re
+	}
+	parsed = captureWildcards(parsed)
+
+	if s.re, err = regexp.Compile(parsed.String()); err != nil || s.re.NumSubexp() != groups {
+		return scoringExpression{re: re, literalLength: s.literalLength}
+	}
+	return s
+}
+
+// isWildcard reports whether re is a repetition of any character, e.g. `.*` of GREEDYDATA or `.*?` of DATA.
+func isWildcard(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return re.Sub[0].Op == syntax.OpAnyCharNotNL || re.Sub[0].Op == syntax.OpAnyChar
+	}
+	return false
+}
+
+// literalLength returns the number of bytes of literal text in every match of re.
+func literalLength(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(string(re.Rune))
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return literalLength(re.Sub[0])
+
+	case syntax.OpRepeat:
+		return re.Min * literalLength(re.Sub[0])
+
+	case syntax.OpConcat:
+		length := 0
+		for _, sub := range re.Sub {
+			length += literalLength(sub)
+		}
+		return length
+
+	case syntax.OpAlternate:
+		length := -1
+		for _, sub := range re.Sub {
+			if l := literalLength(sub); length < 0 || l < length {
+				length = l
+			}
+		}
+		return max(length, 0)
+	}
+
+	// optional elements and character classes
+	return 0
+}
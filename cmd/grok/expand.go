@@ -0,0 +1,113 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-grok"
+)
+
+func runExpand(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("expand", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grok expand -e <expression> [flags]")
+		fmt.Fprintln(fs.Output(), "Prints expanded regular expression, tree of references and captured fields.")
+		fs.PrintDefaults()
+	}
+
+	var patternFlags patternFlags
+	patternFlags.register(fs)
+	expression := fs.String("e", "", "grok expression, e.g %{HTTPD_COMMONLOG}")
+	allCaptures := fs.Bool("all-captures", false, "capture also references without a field name")
+	regexOnly := fs.Bool("regex", false, "print only the expanded regular expression")
+	stats := fs.Bool("stats", false, "print also size of compiled regular expression, number of capture groups and required literals")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if *expression == "" {
+		fmt.Fprintln(stderr, "grok expand: expression not provided, use -e")
+		return exitError
+	}
+
+	g, err := patternFlags.grok()
+	if err != nil {
+		fmt.Fprintf(stderr, "grok expand: %v\n", err)
+		return exitError
+	}
+	expansion, err := g.Expand(*expression, !*allCaptures)
+	if err != nil {
+		fmt.Fprintf(stderr, "grok expand: %v\n", err)
+		return exitError
+	}
+
+	if *regexOnly {
+		fmt.Fprintln(stdout, expansion.Regex)
+		return exitOK
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "regex:\n  %s\n", expansion.Regex)
+
+	sb.WriteString("\nreferences:\n")
+	writeReferences(&sb, expansion.References, 1)
+
+	sb.WriteString("\nfields:\n")
+	for _, field := range expansion.Fields {
+		if field.Type == "" {
+			fmt.Fprintf(&sb, "  %s\n", field.Name)
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s: %s\n", field.Name, field.Type)
+	}
+
+	if *stats {
+		fmt.Fprintf(&sb, "\nstats:\n  length: %d\n  instructions: %d\n  capture groups: %d (named: %d)\n",
+			len(expansion.Regex), expansion.Instructions, expansion.Captures, len(expansion.Fields))
+		quoted := make([]string, len(expansion.RequiredLiterals))
+		for i, literal := range expansion.RequiredLiterals {
+			quoted[i] = strconv.Quote(literal)
+		}
+		fmt.Fprintf(&sb, "  required literals: %s\n", strings.Join(quoted, " "))
+	}
+
+	if _, err := io.WriteString(stdout, sb.String()); err != nil {
+		fmt.Fprintf(stderr, "grok expand: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// writeReferences writes indented tree of references with definitions of referenced patterns.
+func writeReferences(sb *strings.Builder, references []*grok.ExpandedReference, depth int) {
+	for _, reference := range references {
+		fmt.Fprintf(sb, "%s%s = %s\n", strings.Repeat("  ", depth), reference.Raw, reference.Definition)
+		writeReferences(sb, reference.References, depth+1)
+	}
+}
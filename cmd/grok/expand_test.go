@@ -0,0 +1,68 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	patternFile := filepath.Join(dir, "patterns")
+	require.NoError(t, os.WriteFile(patternFile, []byte("PORT %{INT}\nADDRESS %{WORD:host}:%{PORT:port:int}\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"expand", "-patterns", patternFile, "-e", "%{ADDRESS}"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	require.Equal(t, `regex:
+  (?:(?P<host>\b\w+\b):(?P<port>(?:(?:[+-]?(?:[0-9]+)))))
+
+references:
+  %{ADDRESS} = %{WORD:host}:%{PORT:port:int}
+    %{WORD:host} = \b\w+\b
+    %{PORT:port:int} = %{INT}
+      %{INT} = (?:[+-]?(?:[0-9]+))
+
+fields:
+  host
+  port: int
+`, stdout.String())
+
+	stdout.Reset()
+	exit = run([]string{"expand", "-patterns", patternFile, "-e", "%{ADDRESS}", "-all-captures", "-regex"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	require.Equal(t, `(?P<ADDRESS>(?P<host>\b\w+\b):(?P<port>(?P<INT>(?:[+-]?(?:[0-9]+)))))`+"\n", stdout.String())
+
+	stdout.Reset()
+	exit = run([]string{"expand", "-e", "%{WORD:a} %{WORD:b}", "-stats"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	require.Contains(t, stdout.String(), "capture groups: 2 (named: 2)")
+
+	stdout.Reset()
+	exit = run([]string{"expand", "-e", "id=%{WORD:a} took %{INT:b}ms", "-stats"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	require.Contains(t, stdout.String(), `required literals: " took " "id=" "ms"`+"\n")
+
+	require.Equal(t, exitError, run([]string{"expand", "-e", "%{MISSING}"}, nil, &stdout, &stderr))
+	require.Equal(t, exitError, run([]string{"expand"}, nil, &stdout, &stderr))
+}
@@ -0,0 +1,86 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/elastic/go-grok/generate"
+)
+
+func runGenerate(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grok generate -e <expression> [flags]")
+		fmt.Fprintln(fs.Output(), "Writes random lines matching the expression.")
+		fs.PrintDefaults()
+	}
+
+	var patternFlags patternFlags
+	patternFlags.register(fs)
+	expression := fs.String("e", "", "grok expression, e.g %{HTTPD_COMMONLOG}")
+	count := fs.Int("n", 10, "number of lines")
+	seed := fs.Int64("seed", 0, "seed of random generator for reproducible lines, random when 0")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if *expression == "" {
+		fmt.Fprintln(stderr, "grok generate: expression not provided, use -e")
+		return exitError
+	}
+
+	g, err := patternFlags.grok()
+	if err != nil {
+		fmt.Fprintf(stderr, "grok generate: %v\n", err)
+		return exitError
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	gen, err := generate.New(g, *expression, generate.Options{Rand: rand.New(rand.NewSource(*seed))})
+	if err != nil {
+		fmt.Fprintf(stderr, "grok generate: %v\n", err)
+		return exitError
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	for i := 0; i < *count; i++ {
+		line, err := gen.Generate()
+		if err != nil {
+			fmt.Fprintf(stderr, "grok generate: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	return exitOK
+}
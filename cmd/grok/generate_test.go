@@ -0,0 +1,46 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"generate", "-e", "%{HTTPD_COMMONLOG}", "-n", "5", "-seed", "7"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	generated := stdout.String()
+	require.Len(t, strings.Split(strings.TrimSpace(generated), "\n"), 5)
+
+	// generated lines are reproducible and parse
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"generate", "-e", "%{HTTPD_COMMONLOG}", "-n", "5", "-seed", "7"}, nil, &stdout, &stderr))
+	require.Equal(t, generated, stdout.String())
+
+	stdout.Reset()
+	exit = run([]string{"parse", "-quiet", "-e", "^%{HTTPD_COMMONLOG}$"}, strings.NewReader(generated), &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+
+	require.Equal(t, exitError, run([]string{"generate", "-e", "%{MISSING}"}, nil, &stdout, &stderr))
+	require.Equal(t, exitError, run([]string{"generate"}, nil, &stdout, &stderr))
+}
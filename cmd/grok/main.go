@@ -0,0 +1,150 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command grok runs grok expressions from the command line.
+//
+// Usage:
+//
+//	grok <command> [flags] [arguments]
+//
+// Run "grok help" for the list of commands and "grok <command> -h" for flags of a command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/patterns"
+)
+
+const (
+	// exitOK is returned when command succeeded and all lines matched
+	exitOK = 0
+	// exitMismatch is returned when some lines did not match or failed to convert
+	exitMismatch = 1
+	// exitError is returned on invalid usage, invalid expressions and I/O errors
+	exitError = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"parse", "parse lines with an expression and write NDJSON", runParse},
+	{"test", "run pattern test fixtures", runTest},
+	{"expand", "print expanded expression, its references and fields", runExpand},
+	{"stats", "report match rates and field statistics over a corpus", runStats},
+	{"generate", "write random lines matching an expression", runGenerate},
+	{"serve", "serve grok debugger web UI", runServe},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "grok: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: grok <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "grok <command> -h" for flags of a command.`)
+}
+
+// stringList is a flag which can be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// patternFlags are flags selecting pattern definitions shared by commands.
+type patternFlags struct {
+	files  stringList
+	legacy bool
+}
+
+func (p *patternFlags) register(fs *flag.FlagSet) {
+	fs.Var(&p.files, "patterns", "pattern file or directory of pattern files, can be repeated")
+	fs.BoolVar(&p.legacy, "legacy", false, "use bundled patterns with legacy field names instead of ECS")
+}
+
+// grok creates a grok instance with bundled patterns and patterns loaded from files.
+func (p *patternFlags) grok() (*grok.Grok, error) {
+	collection := patterns.ECSv1
+	if p.legacy {
+		collection = patterns.Legacy
+	}
+
+	definitions, err := p.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	return grok.NewCompleteWithCollection(collection, definitions...)
+}
+
+// definitions loads pattern files.
+func (p *patternFlags) definitions() ([]map[string]string, error) {
+	var definitions []map[string]string
+	for _, path := range p.files {
+		loaded, err := grok.LoadPatterns(path)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, loaded)
+	}
+
+	return definitions, nil
+}
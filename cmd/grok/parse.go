@@ -0,0 +1,296 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// tag added to unmatched lines, same as Logstash uses
+const parseFailureTag = "_grokparsefailure"
+
+const (
+	unmatchedSkip = "skip"
+	unmatchedRaw  = "raw"
+	unmatchedFile = "file"
+)
+
+type parseSummary struct {
+	lines     int
+	matched   int
+	unmatched int
+	failed    int
+}
+
+func runParse(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("parse", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grok parse -e <expression> [flags] [file...]")
+		fmt.Fprintln(fs.Output(), "Reads files or stdin when no file or '-' is given and writes one JSON object per matched line,\nlines matched without any captured value are handled as unmatched.")
+		fs.PrintDefaults()
+	}
+
+	var patternFlags patternFlags
+	patternFlags.register(fs)
+	expression := fs.String("e", "", "grok expression, e.g %{HTTPD_COMMONLOG}")
+	typed := fs.Bool("typed", false, "convert values according to type hints")
+	nested := fs.Bool("nested", false, "write dotted field names as nested objects")
+	allCaptures := fs.Bool("all-captures", false, "capture also references without a field name")
+	unmatched := fs.String("unmatched", unmatchedSkip, "handling of unmatched lines: skip, raw (written with "+parseFailureTag+" tag) or file")
+	unmatchedOutput := fs.String("unmatched-output", "", "file unmatched lines are written to when -unmatched=file")
+	quiet := fs.Bool("quiet", false, "do not write summary to stderr")
+	explain := fs.Bool("explain", false, "write explanation of failed match of unmatched lines to stderr")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if *expression == "" {
+		fmt.Fprintln(stderr, "grok parse: expression not provided, use -e")
+		return exitError
+	}
+
+	g, err := patternFlags.grok()
+	if err != nil {
+		fmt.Fprintf(stderr, "grok parse: %v\n", err)
+		return exitError
+	}
+	if err := g.Compile(*expression, !*allCaptures); err != nil {
+		fmt.Fprintf(stderr, "grok parse: %v\n", err)
+		return exitError
+	}
+
+	var unmatchedWriter io.Writer
+	switch *unmatched {
+	case unmatchedSkip, unmatchedRaw:
+	case unmatchedFile:
+		if *unmatchedOutput == "" {
+			fmt.Fprintln(stderr, "grok parse: -unmatched=file requires -unmatched-output")
+			return exitError
+		}
+		f, err := os.Create(*unmatchedOutput)
+		if err != nil {
+			fmt.Fprintf(stderr, "grok parse: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+
+		buffered := bufio.NewWriter(f)
+		defer buffered.Flush()
+		unmatchedWriter = buffered
+	default:
+		fmt.Fprintf(stderr, "grok parse: unknown -unmatched value %q\n", *unmatched)
+		return exitError
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+
+	var summary parseSummary
+	err = forEachLine(fs.Args(), stdin, func(line string) error {
+		summary.lines++
+
+		// the expression is run once, lines matched without any captured value carry nothing
+		// to write and are handled as unmatched
+		var document map[string]interface{}
+		if *typed {
+			values, err := g.ParseTypedString(line)
+			if err != nil {
+				summary.failed++
+				fmt.Fprintf(stderr, "grok parse: line %d: %v\n", summary.lines, err)
+				return writeUnmatched(encoder, unmatchedWriter, *unmatched, line)
+			}
+			document = values
+		} else {
+			values, err := g.ParseString(line)
+			if err != nil {
+				return err
+			}
+			document = make(map[string]interface{}, len(values))
+			for k, v := range values {
+				document[k] = v
+			}
+		}
+
+		if len(document) == 0 {
+			summary.unmatched++
+			if *explain {
+				explanation, err := g.Explain(line)
+				if err != nil {
+					return err
+				}
+				if !explanation.Matched {
+					fmt.Fprintf(stderr, "grok parse: line %d: %s\n", summary.lines, explanation)
+				}
+			}
+			return writeUnmatched(encoder, unmatchedWriter, *unmatched, line)
+		}
+
+		summary.matched++
+		if *nested {
+			document = nest(document)
+		}
+		return encoder.Encode(document)
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "grok parse: %v\n", err)
+		return exitError
+	}
+
+	if !*quiet {
+		fmt.Fprintln(stderr, summary)
+	}
+
+	if summary.unmatched+summary.failed > 0 {
+		return exitMismatch
+	}
+	return exitOK
+}
+
+func (s parseSummary) String() string {
+	rate := 0.0
+	if s.lines > 0 {
+		rate = float64(s.matched) / float64(s.lines) * 100
+	}
+	return fmt.Sprintf("lines: %d, matched: %d (%.1f%%), unmatched: %d, conversion failures: %d", s.lines, s.matched, rate, s.unmatched, s.failed)
+}
+
+func writeUnmatched(encoder *json.Encoder, unmatchedWriter io.Writer, mode, line string) error {
+	switch mode {
+	case unmatchedRaw:
+		return encoder.Encode(map[string]interface{}{
+			"message": line,
+			"tags":    []string{parseFailureTag},
+		})
+	case unmatchedFile:
+		_, err := io.WriteString(unmatchedWriter, line+"\n")
+		return err
+	}
+	return nil
+}
+
+// forEachLine calls fn for every line of files, stdin is read when files are empty or for '-'.
+func forEachLine(files []string, stdin io.Reader, fn func(line string) error) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, file := range files {
+		if file == "-" {
+			if err := readLines(stdin, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		err = readLines(f, fn)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+func readLines(r io.Reader, fn func(line string) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if fnErr := fn(line); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// nest turns dotted field names into nested objects, e.g {"a.b": 1} into {"a": {"b": 1}}.
+// Fields colliding with a non object value are kept with their dotted name.
+func nest(flat map[string]interface{}) map[string]interface{} {
+	nested := make(map[string]interface{}, len(flat))
+
+	// plain names first so that dotted names can detect collisions with them,
+	// dotted names in sorted order so that collisions are resolved deterministically
+	var dotted []string
+	for name, value := range flat {
+		if strings.Contains(name, ".") {
+			dotted = append(dotted, name)
+			continue
+		}
+		nested[name] = value
+	}
+	sort.Strings(dotted)
+
+	for _, name := range dotted {
+		value := flat[name]
+
+		parts := strings.Split(name, ".")
+		current := nested
+		collides := false
+		for _, part := range parts[:len(parts)-1] {
+			child, found := current[part]
+			if !found {
+				child = make(map[string]interface{})
+				current[part] = child
+			}
+
+			object, ok := child.(map[string]interface{})
+			if !ok {
+				collides = true
+				break
+			}
+			current = object
+		}
+
+		last := parts[len(parts)-1]
+		if _, found := current[last]; collides || found {
+			nested[name] = value
+			continue
+		}
+		current[last] = value
+	}
+
+	return nested
+}
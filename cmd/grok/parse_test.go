@@ -0,0 +1,179 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	commonLogLine = `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`
+	otherLine     = "not an access log"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Args     []string
+		Input    string
+		Expected string
+		Exit     int
+	}{
+		{
+			"flat strings",
+			[]string{"-e", "%{IP:client} %{WORD:method} %{NUMBER:bytes:int}"},
+			"10.0.0.1 GET 12\n",
+			`{"bytes":"12","client":"10.0.0.1","method":"GET"}` + "\n",
+			exitOK,
+		},
+		{
+			"typed",
+			[]string{"-e", "%{IP:client} %{WORD:method} %{NUMBER:bytes:int}", "-typed"},
+			"10.0.0.1 GET 12\r\n",
+			`{"bytes":12,"client":"10.0.0.1","method":"GET"}` + "\n",
+			exitOK,
+		},
+		{
+			"nested",
+			[]string{"-e", "%{HTTPD_COMMONLOG}", "-typed", "-nested"},
+			commonLogLine,
+			`{"http":{"request":{"method":"GET"},"response":{"body":{"size":2326},"status_code":200},"version":"1.0"},"source":{"address":"127.0.0.1"},"timestamp":"10/Oct/2000:13:55:36 -0700","url":{"original":"/apache_pb.gif"},"user":{"name":"frank"}}` + "\n",
+			exitOK,
+		},
+		{
+			"legacy field names",
+			[]string{"-e", "%{HTTPD_COMMONLOG}", "-legacy"},
+			commonLogLine,
+			`{"auth":"frank","bytes":"2326","clientip":"127.0.0.1","httpversion":"1.0","request":"/apache_pb.gif","response":"200","timestamp":"10/Oct/2000:13:55:36 -0700","verb":"GET"}` + "\n",
+			exitOK,
+		},
+		{
+			"unmatched skipped",
+			[]string{"-e", "%{IP:client}$"},
+			"10.0.0.1\n" + otherLine + "\n",
+			`{"client":"10.0.0.1"}` + "\n",
+			exitMismatch,
+		},
+		{
+			"unmatched raw",
+			[]string{"-e", "%{IP:client}$", "-unmatched", "raw"},
+			otherLine + "\n10.0.0.1",
+			`{"message":"not an access log","tags":["_grokparsefailure"]}` + "\n" + `{"client":"10.0.0.1"}` + "\n",
+			exitMismatch,
+		},
+		{
+			"matched without captured values",
+			[]string{"-e", "%{IP:client}?not", "-unmatched", "raw"},
+			otherLine + "\n",
+			`{"message":"not an access log","tags":["_grokparsefailure"]}` + "\n",
+			exitMismatch,
+		},
+		{
+			"conversion failure",
+			[]string{"-e", "%{WORD:n:int}", "-typed", "-unmatched", "raw"},
+			"abc\n",
+			`{"message":"abc","tags":["_grokparsefailure"]}` + "\n",
+			exitMismatch,
+		},
+		{
+			"invalid expression",
+			[]string{"-e", "%{MISSING}"},
+			"",
+			"",
+			exitError,
+		},
+		{
+			"missing expression",
+			nil,
+			"",
+			"",
+			exitError,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exit := run(append([]string{"parse", "-quiet"}, tt.Args...), strings.NewReader(tt.Input), &stdout, &stderr)
+			require.Equal(t, tt.Exit, exit, stderr.String())
+			require.Equal(t, tt.Expected, stdout.String())
+		})
+	}
+}
+
+func TestParse_FilesAndSummary(t *testing.T) {
+	dir := t.TempDir()
+	patternFile := filepath.Join(dir, "patterns")
+	input := filepath.Join(dir, "input.log")
+	unmatched := filepath.Join(dir, "unmatched.log")
+	require.NoError(t, os.WriteFile(patternFile, []byte("# custom\nAPP_ID [a-z]+-%{INT}\n"), 0o600))
+	require.NoError(t, os.WriteFile(input, []byte("id=abc-1\nid=x\nid=def-22\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"parse", "-patterns", patternFile, "-e", "id=%{APP_ID:app.id}", "-nested", "-unmatched", "file", "-unmatched-output", unmatched, input}, strings.NewReader(""), &stdout, &stderr)
+	require.Equal(t, exitMismatch, exit)
+	require.Equal(t, `{"app":{"id":"abc-1"}}`+"\n"+`{"app":{"id":"def-22"}}`+"\n", stdout.String())
+	require.Equal(t, "lines: 3, matched: 2 (66.7%), unmatched: 1, conversion failures: 0\n", stderr.String())
+
+	written, err := os.ReadFile(unmatched)
+	require.NoError(t, err)
+	require.Equal(t, "id=x\n", string(written))
+}
+
+func TestNest(t *testing.T) {
+	nested := nest(map[string]interface{}{
+		"a":     1,
+		"a.b":   2,
+		"c.d":   3,
+		"c.e.f": 4,
+		"c.e":   5,
+	})
+
+	require.Equal(t, map[string]interface{}{
+		"a":   1,
+		"a.b": 2,
+		"c": map[string]interface{}{
+			"d": 3,
+			"e": 5,
+		},
+		"c.e.f": 4,
+	}, nested)
+}
+
+func TestUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"help"}, nil, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "parse")
+
+	require.Equal(t, exitError, run([]string{"unknown"}, nil, &stdout, &stderr))
+	require.Equal(t, exitError, run(nil, nil, &stdout, &stderr))
+}
+
+func TestParse_Explain(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"parse", "-quiet", "-explain", "-e", "id=%{WORD:app.name}-%{INT:app.id}"}, strings.NewReader("id=abc-1\nid=abc\n"), &stdout, &stderr)
+	require.Equal(t, exitMismatch, exit)
+	require.Equal(t, `{"app.id":"1","app.name":"abc"}`+"\n", stdout.String())
+	require.Equal(t, "grok parse: line 2: failed to match \"-\" at offset 6\n  app.name: \"abc\"\n", stderr.String())
+}
@@ -0,0 +1,289 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/patterns"
+)
+
+//go:embed serve.html
+var debuggerPage []byte
+
+const (
+	// maxDebugRequestSize limits size of debug request body
+	maxDebugRequestSize = 1 << 20
+	// maxDebugLines limits number of sample lines of a debug request
+	maxDebugLines = 1000
+)
+
+func runServe(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grok serve [flags]")
+		fmt.Fprintln(fs.Output(), "Serves grok debugger web UI, patterns loaded with -patterns are available to all expressions.")
+		fs.PrintDefaults()
+	}
+
+	var patternFlags patternFlags
+	fs.Var(&patternFlags.files, "patterns", "pattern file or directory of pattern files, can be repeated")
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	definitions, err := patternFlags.definitions()
+	if err != nil {
+		fmt.Fprintf(stderr, "grok serve: %v\n", err)
+		return exitError
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newDebugger(definitions),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(stdout, "grok debugger listening on http://%s\n", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintf(stderr, "grok serve: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// debugRequest is a request of the debugger UI.
+type debugRequest struct {
+	Expression string `json:"expression"`
+	// Patterns are custom definitions in pattern file format
+	Patterns    string `json:"patterns"`
+	Sample      string `json:"sample"`
+	AllCaptures bool   `json:"all_captures"`
+	Legacy      bool   `json:"legacy"`
+}
+
+type debugResponse struct {
+	Error   string        `json:"error,omitempty"`
+	Regex   string        `json:"regex,omitempty"`
+	Fields  []debugField  `json:"fields,omitempty"`
+	Results []debugResult `json:"results,omitempty"`
+}
+
+type debugField struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+type debugResult struct {
+	Line        string                 `json:"line"`
+	Matched     bool                   `json:"matched"`
+	Captures    map[string]string      `json:"captures,omitempty"`
+	Typed       map[string]interface{} `json:"typed,omitempty"`
+	TypeError   string                 `json:"type_error,omitempty"`
+	Spans       []debugSpan            `json:"spans,omitempty"`
+	Explanation *debugExplanation      `json:"explanation,omitempty"`
+}
+
+// debugSpan offsets are in characters rather than bytes as the UI works with characters.
+type debugSpan struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+type debugExplanation struct {
+	Message   string            `json:"message"`
+	Failed    string            `json:"failed"`
+	Reference string            `json:"reference,omitempty"`
+	Path      []string          `json:"path,omitempty"`
+	Offset    int               `json:"offset"`
+	Captures  map[string]string `json:"captures,omitempty"`
+}
+
+// debugger serves the debugger UI and its API.
+type debugger struct {
+	// definitions are pattern files loaded at start, available to all requests
+	definitions []map[string]string
+}
+
+func newDebugger(definitions []map[string]string) http.Handler {
+	d := &debugger{definitions: definitions}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.serveUI)
+	mux.HandleFunc("/api/debug", d.serveDebug)
+	return mux
+}
+
+func (d *debugger) serveUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(debuggerPage)
+}
+
+func (d *debugger) serveDebug(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeJSON(w, http.StatusMethodNotAllowed, debugResponse{Error: "method not allowed"})
+		return
+	}
+
+	var request debugRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDebugRequestSize))
+	if err := decoder.Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, debugResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	response, err := d.debug(request)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, debugResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// debug compiles expression of request and runs it over sample lines.
+func (d *debugger) debug(request debugRequest) (*debugResponse, error) {
+	if request.Expression == "" {
+		return nil, errors.New("expression not provided")
+	}
+
+	custom, err := grok.ReadPatterns(strings.NewReader(request.Patterns))
+	if err != nil {
+		return nil, fmt.Errorf("custom patterns: %w", err)
+	}
+
+	collection := patterns.ECSv1
+	if request.Legacy {
+		collection = patterns.Legacy
+	}
+	g, err := grok.NewCompleteWithCollection(collection, append(append([]map[string]string(nil), d.definitions...), custom)...)
+	if err != nil {
+		return nil, err
+	}
+
+	expansion, err := g.Expand(request.Expression, !request.AllCaptures)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Compile(request.Expression, !request.AllCaptures); err != nil {
+		return nil, err
+	}
+
+	response := &debugResponse{Regex: expansion.Regex, Results: []debugResult{}}
+	for _, field := range g.Fields() {
+		response.Fields = append(response.Fields, debugField{Name: field.Name, Type: field.Type})
+	}
+
+	var lines []string
+	for _, line := range strings.Split(request.Sample, "\n") {
+		if line = strings.TrimSuffix(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > maxDebugLines {
+		return nil, fmt.Errorf("too many sample lines, at most %d are supported", maxDebugLines)
+	}
+
+	for _, line := range lines {
+		result, err := debugLine(g, line)
+		if err != nil {
+			return nil, err
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+func debugLine(g *grok.Grok, line string) (debugResult, error) {
+	result := debugResult{Line: line, Matched: g.MatchString(line)}
+
+	if !result.Matched {
+		explanation, err := g.Explain(line)
+		if err != nil {
+			return result, err
+		}
+		result.Explanation = &debugExplanation{
+			Message:   explanation.String(),
+			Failed:    explanation.Failed,
+			Reference: explanation.Reference,
+			Path:      explanation.Path,
+			Offset:    utf8.RuneCountInString(line[:explanation.Offset]),
+			Captures:  explanation.Captures,
+		}
+		return result, nil
+	}
+
+	captures, err := g.ParseString(line)
+	if err != nil {
+		return result, err
+	}
+	result.Captures = captures
+
+	if typed, err := g.ParseTypedString(line); err != nil {
+		result.TypeError = err.Error()
+	} else {
+		result.Typed = typed
+	}
+
+	for _, span := range g.Spans(line) {
+		result.Spans = append(result.Spans, debugSpan{
+			Field: span.Field,
+			Start: utf8.RuneCountInString(line[:span.Start]),
+			End:   utf8.RuneCountInString(line[:span.End]),
+		})
+	}
+
+	return result, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(v)
+}
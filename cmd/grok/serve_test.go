@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	server := httptest.NewServer(newDebugger([]map[string]string{{"APP_ID": `[a-z]+-%{INT}`}}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	page, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, string(page), "<title>Grok Debugger</title>")
+
+	resp, err = http.Get(server.URL + "/missing")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	testCases := []struct {
+		Name     string
+		Request  string
+		Status   int
+		Expected string
+	}{
+		{
+			"match",
+			`{"expression": "%{USER:user}: id=%{APP_ID:app.id} took=%{NUMBER:took:float}", "sample": "žofia: id=abc-1 took=0.5\r\n"}`,
+			http.StatusOK,
+			`{"regex":"(?P<user>(?:[a-zA-Z0-9._-]+)): id=(?P<app___id>[a-z]+-(?:(?:[+-]?(?:[0-9]+)))) took=(?P<took>(?:(?:(?:[+-]?(?:[0-9]+(?:\\.[0-9]+)?)|\\.[0-9]+))))","fields":[{"name":"user"},{"name":"app.id"},{"name":"took","type":"float"}],"results":[{"line":"žofia: id=abc-1 took=0.5","matched":true,"captures":{"app.id":"abc-1","took":"0.5","user":"ofia"},"typed":{"app.id":"abc-1","took":0.5,"user":"ofia"},"spans":[{"field":"user","start":1,"end":5},{"field":"app.id","start":10,"end":15},{"field":"took","start":21,"end":24}]}]}`,
+		},
+		{
+			"explained failure and type error",
+			`{"expression": "%{WORD:word:int} %{INT:n}", "sample": "abc 1\nabc x", "patterns": "# custom\n"}`,
+			http.StatusOK,
+			`{"regex":"(?P<word>\\b\\w+\\b) (?P<n>(?:[+-]?(?:[0-9]+)))","fields":[{"name":"word","type":"int"},{"name":"n"}],"results":[{"line":"abc 1","matched":true,"captures":{"n":"1","word":"abc"},"type_error":"strconv.Atoi: parsing \"abc\": invalid syntax","spans":[{"field":"word","start":0,"end":3},{"field":"n","start":4,"end":5}]},{"line":"abc x","matched":false,"explanation":{"message":"failed to match \"[0-9]+\" at offset 4 of %{INT:n} in INT\n  word: \"abc\"","failed":"[0-9]+","reference":"%{INT:n}","path":["INT"],"offset":4,"captures":{"word":"abc"}}}]}`,
+		},
+		{
+			"legacy",
+			`{"expression": "%{SYSLOGPROG}", "sample": "sshd[12]", "legacy": true}`,
+			http.StatusOK,
+			`{"regex":"(?:(?P<program>[!-Z\\\\^-~]+)(?:\\[(?P<pid>\\b[1-9][0-9]*\\b)\\])?)","fields":[{"name":"program"},{"name":"pid"}],"results":[{"line":"sshd[12]","matched":true,"captures":{"pid":"12","program":"sshd"},"typed":{"pid":"12","program":"sshd"},"spans":[{"field":"program","start":0,"end":4},{"field":"pid","start":5,"end":7}]}]}`,
+		},
+		{
+			"invalid expression",
+			`{"expression": "%{MISSING}"}`,
+			http.StatusUnprocessableEntity,
+			`{"error":"pattern definition \"MISSING\" unknown: parsing failed"}`,
+		},
+		{
+			"invalid custom patterns",
+			`{"expression": "%{INT}", "patterns": "INVALID"}`,
+			http.StatusUnprocessableEntity,
+			``,
+		},
+		{
+			"invalid request",
+			`{"expression": `,
+			http.StatusBadRequest,
+			``,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/api/debug", "application/json", strings.NewReader(tt.Request))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.Status, resp.StatusCode, string(body))
+			if tt.Expected != "" {
+				require.JSONEq(t, tt.Expected, string(body))
+			} else {
+				var response debugResponse
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.NotEmpty(t, response.Error)
+			}
+		})
+	}
+
+	resp, err = http.Get(server.URL + "/api/debug")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
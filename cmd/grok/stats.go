@@ -0,0 +1,271 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/learn"
+)
+
+// expressionStats are statistics of an expression collected over a corpus.
+type expressionStats struct {
+	expression string
+	g          *grok.Grok
+	fields     []grok.Field
+	matched    int
+	elapsed    time.Duration
+	filled     map[string]int
+	distinct   map[string]map[string]struct{}
+	failures   map[string]int
+	slowest    []slowLine
+}
+
+type slowLine struct {
+	number  int
+	line    string
+	elapsed time.Duration
+}
+
+func runStats(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: grok stats -e <expression> [-e <expression>...] [flags] [file...]")
+		fmt.Fprintln(fs.Output(), "Runs expressions over files or stdin and reports match rates, field statistics and unmatched lines,\nlines matched without any captured value are counted as unmatched.")
+		fs.PrintDefaults()
+	}
+
+	var patternFlags patternFlags
+	patternFlags.register(fs)
+	var expressions stringList
+	fs.Var(&expressions, "e", "grok expression, can be repeated")
+	slowest := fs.Int("slowest", 5, "number of slowest lines reported per expression, 0 disables timing")
+	maxDistinct := fs.Int("max-distinct", 10000, "distinct values counted per field at most")
+	clusters := fs.Int("unmatched-clusters", 5, "number of clusters of unmatched lines reported")
+	maxUnmatched := fs.Int("max-unmatched", 10000, "unmatched lines kept for clustering at most")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if len(expressions) == 0 {
+		fmt.Fprintln(stderr, "grok stats: expression not provided, use -e")
+		return exitError
+	}
+
+	stats := make([]*expressionStats, 0, len(expressions))
+	for _, expression := range expressions {
+		g, err := patternFlags.grok()
+		if err == nil {
+			err = g.Compile(expression, true)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "grok stats: %v\n", err)
+			return exitError
+		}
+
+		stats = append(stats, &expressionStats{
+			expression: expression,
+			g:          g,
+			fields:     g.Fields(),
+			filled:     make(map[string]int),
+			distinct:   make(map[string]map[string]struct{}),
+			failures:   make(map[string]int),
+		})
+	}
+
+	var lines int
+	var unmatched []string
+	var unmatchedCount int
+	err := forEachLine(fs.Args(), stdin, func(line string) error {
+		lines++
+
+		matchedAny := false
+		for _, s := range stats {
+			if s.observe(lines, line, *slowest, *maxDistinct) {
+				matchedAny = true
+			}
+		}
+
+		if !matchedAny {
+			unmatchedCount++
+			if len(unmatched) < *maxUnmatched {
+				unmatched = append(unmatched, line)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "grok stats: %v\n", err)
+		return exitError
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "lines: %d\n", lines)
+	for _, s := range stats {
+		s.write(&sb, lines, *maxDistinct)
+	}
+
+	fmt.Fprintf(&sb, "\nunmatched: %d (%s)\n", unmatchedCount, percent(unmatchedCount, lines))
+	if len(unmatched) > 0 && *clusters > 0 {
+		templates, err := learn.Learn(unmatched, learn.Options{})
+		if err != nil {
+			fmt.Fprintf(stderr, "grok stats: %v\n", err)
+			return exitError
+		}
+		if len(templates) > *clusters {
+			templates = templates[:*clusters]
+		}
+		for _, template := range templates {
+			fmt.Fprintf(&sb, "  %d similar to %q\n    %s\n", template.Lines, template.Example, template.Expression)
+		}
+	}
+
+	if _, err := io.WriteString(stdout, sb.String()); err != nil {
+		fmt.Fprintf(stderr, "grok stats: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// observe matches line and records statistics, reports whether line matched.
+func (s *expressionStats) observe(number int, line string, slowest, maxDistinct int) bool {
+	// timing is reported only together with the slowest lines
+	var start time.Time
+	if slowest > 0 {
+		start = time.Now()
+	}
+	// the expression is run once, lines matched without any captured value are counted as unmatched
+	values, _ := s.g.ParseString(line)
+	matched := len(values) > 0
+	if slowest > 0 {
+		elapsed := time.Since(start)
+		s.elapsed += elapsed
+		s.recordSlow(slowLine{number: number, line: line, elapsed: elapsed}, slowest)
+	}
+
+	if !matched {
+		return false
+	}
+	s.matched++
+
+	for _, field := range s.fields {
+		value, found := values[field.Name]
+		if !found {
+			continue
+		}
+		s.filled[field.Name]++
+
+		distinct, found := s.distinct[field.Name]
+		if !found {
+			distinct = make(map[string]struct{})
+			s.distinct[field.Name] = distinct
+		}
+		if len(distinct) < maxDistinct {
+			distinct[value] = struct{}{}
+		}
+
+		if field.Type != "" {
+			if _, err := grok.Convert(value, field.Type); err != nil {
+				s.failures[field.Name]++
+			}
+		}
+	}
+
+	return true
+}
+
+// recordSlow keeps n slowest lines sorted from the slowest one.
+func (s *expressionStats) recordSlow(line slowLine, n int) {
+	if n <= 0 {
+		return
+	}
+	if len(s.slowest) == n && s.slowest[n-1].elapsed >= line.elapsed {
+		return
+	}
+
+	i := sort.Search(len(s.slowest), func(i int) bool {
+		return s.slowest[i].elapsed < line.elapsed
+	})
+	s.slowest = append(s.slowest, slowLine{})
+	copy(s.slowest[i+1:], s.slowest[i:])
+	s.slowest[i] = line
+	if len(s.slowest) > n {
+		s.slowest = s.slowest[:n]
+	}
+}
+
+func (s *expressionStats) write(sb *strings.Builder, lines, maxDistinct int) {
+	fmt.Fprintf(sb, "\nexpression: %s\n", s.expression)
+	fmt.Fprintf(sb, "  matched: %d (%s)\n", s.matched, percent(s.matched, lines))
+	if len(s.slowest) > 0 {
+		fmt.Fprintf(sb, "  time: %s\n", s.elapsed)
+	}
+
+	if len(s.fields) > 0 {
+		sb.WriteString("  fields:\n")
+		w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "    field\ttype\tfilled\tdistinct\tconversion failures")
+		for _, field := range s.fields {
+			distinct := strconv.Itoa(len(s.distinct[field.Name]))
+			if len(s.distinct[field.Name]) >= maxDistinct {
+				distinct += "+"
+			}
+
+			failures := "-"
+			if field.Type != "" {
+				failures = strconv.Itoa(s.failures[field.Name])
+			}
+
+			fieldType := field.Type
+			if fieldType == "" {
+				fieldType = "-"
+			}
+
+			fmt.Fprintf(w, "    %s\t%s\t%s\t%s\t%s\n", field.Name, fieldType, percent(s.filled[field.Name], s.matched), distinct, failures)
+		}
+		w.Flush()
+	}
+
+	if len(s.slowest) > 0 {
+		sb.WriteString("  slowest lines:\n")
+		for _, slow := range s.slowest {
+			fmt.Fprintf(sb, "    %s line %d: %q\n", slow.elapsed, slow.number, slow.line)
+		}
+	}
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)/float64(total)*100)
+}
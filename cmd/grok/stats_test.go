@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const statsInput = `id=abc-1 took=1
+id=abc-2 took=x
+id=abc-1 took=3
+error: disk full on /dev/sda
+error: disk full on /dev/sdb
+warn something
+`
+
+func TestStats(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"stats", "-slowest", "0",
+		"-e", "id=%{NOTSPACE:app.id} took=%{WORD:took:int}",
+		"-e", "error: %{GREEDYDATA:message}",
+		"-e", "%{WORD:level}: %{WORD:other}?",
+	}, strings.NewReader(statsInput), &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	require.Equal(t, `lines: 6
+
+expression: id=%{NOTSPACE:app.id} took=%{WORD:took:int}
+  matched: 3 (50.0%)
+  fields:
+    field   type  filled  distinct  conversion failures
+    app.id  -     100.0%  2         -
+    took    int   100.0%  3         1
+
+expression: error: %{GREEDYDATA:message}
+  matched: 2 (33.3%)
+  fields:
+    field    type  filled  distinct  conversion failures
+    message  -     100.0%  2         -
+
+expression: %{WORD:level}: %{WORD:other}?
+  matched: 2 (33.3%)
+  fields:
+    field  type  filled  distinct  conversion failures
+    level  -     100.0%  1         -
+    other  -     100.0%  1         -
+
+unmatched: 1 (16.7%)
+  1 similar to "warn something"
+    ^warn\s+something$
+`, stdout.String())
+}
+
+func TestStats_Slowest(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"stats", "-slowest", "2", "-max-distinct", "1", "-e", "id=%{NOTSPACE:app.id}"}, strings.NewReader(statsInput), &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+
+	output := stdout.String()
+	require.Contains(t, output, "  time: ")
+	require.Contains(t, output, "app.id  -     100.0%  1+")
+	require.Equal(t, 2, strings.Count(output[strings.Index(output, "slowest lines:"):strings.Index(output, "unmatched:")], " line "))
+	require.Contains(t, output, "unmatched: 3 (50.0%)")
+
+	require.Equal(t, exitError, run([]string{"stats"}, nil, &stdout, &stderr))
+	require.Equal(t, exitError, run([]string{"stats", "-e", "%{MISSING}"}, nil, &stdout, &stderr))
+}
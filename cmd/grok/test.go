@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/elastic/go-grok/fixture"
+)
+
+func runTest(args []string, _ io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: grok test [flags] <fixture file or directory>...")
+		fmt.Fprintln(flags.Output(), "Runs pattern test fixtures in YAML (.yaml, .yml) or JSON (.json) format.")
+		flags.PrintDefaults()
+	}
+
+	var patternFlags patternFlags
+	patternFlags.register(flags)
+	verbose := flags.Bool("v", false, "print also passed tests")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	definitions, err := patternFlags.definitions()
+	if err != nil {
+		fmt.Fprintf(stderr, "grok test: %v\n", err)
+		return exitError
+	}
+	opts := fixture.Options{Patterns: definitions, Legacy: patternFlags.legacy}
+
+	files, err := fixtureFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "grok test: %v\n", err)
+		return exitError
+	}
+
+	var passed, failed int
+	for _, path := range files {
+		f, err := fixture.Load(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "grok test: %v\n", err)
+			return exitError
+		}
+
+		for _, result := range fixture.Run(f, opts) {
+			if result.Passed() {
+				passed++
+				if *verbose {
+					fmt.Fprintf(stdout, "%s: %s\n", path, result)
+				}
+				continue
+			}
+
+			failed++
+			fmt.Fprintf(stdout, "%s: %s\n", path, result)
+		}
+	}
+
+	fmt.Fprintf(stdout, "passed: %d, failed: %d\n", passed, failed)
+	if failed > 0 {
+		return exitMismatch
+	}
+	return exitOK
+}
+
+// fixtureFiles returns fixture files of paths, directories are searched recursively.
+func fixtureFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+
+			// explicitly listed files are loaded regardless of extension and fail on unsupported ones
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, file)
+			default:
+				if file == path {
+					files = append(files, file)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTest(t *testing.T) {
+	dir := t.TempDir()
+	patternFile := filepath.Join(dir, "patterns")
+	require.NoError(t, os.WriteFile(patternFile, []byte("APP_ID [a-z]+-%{INT}\n"), 0o600))
+
+	fixtures := filepath.Join(dir, "fixtures")
+	require.NoError(t, os.Mkdir(fixtures, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "app.yaml"), []byte(`
+tests:
+  - name: app id
+    pattern: 'id=%{APP_ID:app.id}'
+    lines: ['id=abc-1']
+    fields:
+      app.id: abc-1
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtures, "README.md"), []byte("not a fixture"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	exit := run([]string{"test", "-patterns", patternFile, "-v", fixtures}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, exit, stderr.String())
+	require.Equal(t, filepath.Join(fixtures, "app.yaml")+": PASS app id\npassed: 1, failed: 0\n", stdout.String())
+
+	failing := filepath.Join(dir, "failing.json")
+	require.NoError(t, os.WriteFile(failing, []byte(`{"tests": [{"name": "app id", "pattern": "id=%{APP_ID:app.id}", "lines": ["id=abc-1"], "fields": {"app.id": "abc-2"}}]}`), 0o600))
+
+	stdout.Reset()
+	exit = run([]string{"test", "-patterns", patternFile, fixtures, failing}, nil, &stdout, &stderr)
+	require.Equal(t, exitMismatch, exit, stderr.String())
+	require.Equal(t, failing+`: FAIL app id
+  line: "id=abc-1"
+  ~ app.id: expected "abc-2" (string), got "abc-1" (string)
+passed: 1, failed: 1
+`, stdout.String())
+
+	// patterns are missing
+	stdout.Reset()
+	require.Equal(t, exitMismatch, run([]string{"test", fixtures}, nil, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "unknown")
+
+	// fixtures written for legacy field names
+	legacy := filepath.Join(dir, "legacy.yaml")
+	require.NoError(t, os.WriteFile(legacy, []byte(`
+tests:
+  - name: client
+    pattern: '%{HTTPD_COMMONLOG}'
+    partial: true
+    lines: ['127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 200 2326']
+    fields:
+      clientip: 127.0.0.1
+`), 0o600))
+	stdout.Reset()
+	require.Equal(t, exitMismatch, run([]string{"test", legacy}, nil, &stdout, &stderr))
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{"test", "-legacy", legacy}, nil, &stdout, &stderr), stdout.String())
+
+	require.Equal(t, exitError, run([]string{"test", filepath.Join(fixtures, "README.md")}, nil, &stdout, &stderr))
+	require.Equal(t, exitError, run([]string{"test", filepath.Join(dir, "missing.yaml")}, nil, &stdout, &stderr))
+	require.Equal(t, exitError, run([]string{"test"}, nil, &stdout, &stderr))
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command grokd serves grok parsing over a local HTTP/JSON API.
+//
+// Pattern sets and named expressions are registered at runtime, expressions are then used
+// to parse single lines or NDJSON batches:
+//
+//	PUT    /v1/patterns/{set}             register or replace pattern set
+//	GET    /v1/patterns                   list pattern sets
+//	GET    /v1/patterns/{set}             get pattern set
+//	DELETE /v1/patterns/{set}             delete pattern set not used by any expression
+//	PUT    /v1/expressions/{name}         compile and register expression
+//	GET    /v1/expressions                list expressions
+//	GET    /v1/expressions/{name}         get expression and its fields
+//	DELETE /v1/expressions/{name}         delete expression
+//	POST   /v1/expressions/{name}/parse   parse single line
+//	POST   /v1/expressions/{name}/batch   parse NDJSON batch of lines
+//	GET    /v1/metrics                    per expression metrics
+//	GET    /healthz                       health check
+//
+// Replacing a pattern set recompiles all expressions using it, the replacement is rejected
+// when any of them fails to compile.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("grokd", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	addr := fs.String("addr", "127.0.0.1:8081", "address to listen on")
+	var limits limits
+	fs.Int64Var(&limits.maxBodySize, "max-body-size", defaultLimits.maxBodySize, "maximum size of request body in bytes")
+	fs.IntVar(&limits.maxLineLength, "max-line-length", defaultLimits.maxLineLength, "maximum length of parsed line in bytes")
+	fs.IntVar(&limits.maxBatchLines, "max-batch-lines", defaultLimits.maxBatchLines, "maximum number of lines of a batch")
+	fs.IntVar(&limits.maxExpressions, "max-expressions", defaultLimits.maxExpressions, "maximum number of registered expressions")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newServer(limits),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(stdout, "grokd listening on http://%s\n", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintf(stderr, "grokd: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,596 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/patterns"
+)
+
+// limits protect the server from oversized requests.
+type limits struct {
+	maxBodySize    int64
+	maxLineLength  int
+	maxBatchLines  int
+	maxExpressions int
+}
+
+var defaultLimits = limits{
+	maxBodySize:    10 << 20,
+	maxLineLength:  64 << 10,
+	maxBatchLines:  10000,
+	maxExpressions: 1000,
+}
+
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("conflict")
+	errInvalid  = errors.New("invalid request")
+	errTooLarge = errors.New("request too large")
+)
+
+// patternSet is a named set of pattern definitions.
+type patternSet struct {
+	Definitions map[string]string `json:"definitions"`
+}
+
+// expressionSpec is a named expression as registered by clients.
+type expressionSpec struct {
+	Expression string `json:"expression"`
+	// Patterns are names of pattern sets available to the expression, later sets override earlier ones.
+	Patterns []string `json:"patterns,omitempty"`
+	// Legacy selects bundled patterns with legacy field names instead of ECS.
+	Legacy bool `json:"legacy,omitempty"`
+	// AllCaptures captures also references without a field name.
+	AllCaptures bool `json:"all_captures,omitempty"`
+}
+
+// expression is a compiled expression with its metrics.
+type expression struct {
+	spec    expressionSpec
+	g       *grok.Grok
+	metrics *metrics
+}
+
+type metrics struct {
+	lines              atomic.Int64
+	matched            atomic.Int64
+	unmatched          atomic.Int64
+	conversionFailures atomic.Int64
+	nanoseconds        atomic.Int64
+}
+
+type metricsSnapshot struct {
+	Lines              int64 `json:"lines"`
+	Matched            int64 `json:"matched"`
+	Unmatched          int64 `json:"unmatched"`
+	ConversionFailures int64 `json:"conversion_failures"`
+	// ParseTime is the total time spent parsing lines in nanoseconds.
+	ParseTime int64 `json:"parse_time_ns"`
+}
+
+func (m *metrics) snapshot() metricsSnapshot {
+	return metricsSnapshot{
+		Lines:              m.lines.Load(),
+		Matched:            m.matched.Load(),
+		Unmatched:          m.unmatched.Load(),
+		ConversionFailures: m.conversionFailures.Load(),
+		ParseTime:          m.nanoseconds.Load(),
+	}
+}
+
+type parseRequest struct {
+	Line string `json:"line"`
+}
+
+type parseResult struct {
+	Matched bool                   `json:"matched"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+type expressionInfo struct {
+	Name string `json:"name"`
+	expressionSpec
+	Fields []fieldInfo `json:"fields"`
+}
+
+type fieldInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+func newExpressionInfo(name string, spec expressionSpec, g *grok.Grok) expressionInfo {
+	info := expressionInfo{Name: name, expressionSpec: spec, Fields: []fieldInfo{}}
+	for _, field := range g.Fields() {
+		info.Fields = append(info.Fields, fieldInfo{Name: field.Name, Type: field.Type})
+	}
+	return info
+}
+
+type server struct {
+	limits limits
+
+	mu          sync.RWMutex
+	sets        map[string]patternSet
+	expressions map[string]*expression
+}
+
+func newServer(l limits) *server {
+	return &server{
+		limits:      l,
+		sets:        make(map[string]patternSet),
+		expressions: make(map[string]*expression),
+	}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.limits.maxBodySize)
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "healthz":
+		s.route(w, r, map[string]http.HandlerFunc{http.MethodGet: s.health})
+	case path == "v1/metrics":
+		s.route(w, r, map[string]http.HandlerFunc{http.MethodGet: s.getMetrics})
+	case path == "v1/patterns":
+		s.route(w, r, map[string]http.HandlerFunc{http.MethodGet: s.listPatternSets})
+	case len(parts) == 3 && parts[0] == "v1" && parts[1] == "patterns" && parts[2] != "":
+		name := parts[2]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:    func(w http.ResponseWriter, r *http.Request) { s.getPatternSet(w, name) },
+			http.MethodPut:    func(w http.ResponseWriter, r *http.Request) { s.putPatternSet(w, r, name) },
+			http.MethodDelete: func(w http.ResponseWriter, r *http.Request) { s.deletePatternSet(w, name) },
+		})
+	case path == "v1/expressions":
+		s.route(w, r, map[string]http.HandlerFunc{http.MethodGet: s.listExpressions})
+	case len(parts) == 3 && parts[0] == "v1" && parts[1] == "expressions" && parts[2] != "":
+		name := parts[2]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodGet:    func(w http.ResponseWriter, r *http.Request) { s.getExpression(w, name) },
+			http.MethodPut:    func(w http.ResponseWriter, r *http.Request) { s.putExpression(w, r, name) },
+			http.MethodDelete: func(w http.ResponseWriter, r *http.Request) { s.deleteExpression(w, name) },
+		})
+	case len(parts) == 4 && parts[0] == "v1" && parts[1] == "expressions" && parts[3] == "parse":
+		name := parts[2]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) { s.parse(w, r, name) },
+		})
+	case len(parts) == 4 && parts[0] == "v1" && parts[1] == "expressions" && parts[3] == "batch":
+		name := parts[2]
+		s.route(w, r, map[string]http.HandlerFunc{
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) { s.parseBatch(w, r, name) },
+		})
+	default:
+		writeError(w, fmt.Errorf("%s: %w", r.URL.Path, errNotFound))
+	}
+}
+
+// route dispatches request by method.
+func (s *server) route(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, found := handlers[r.Method]
+	if !found {
+		methods := make([]string, 0, len(handlers))
+		for method := range handlers {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	handler(w, r)
+}
+
+func (s *server) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) listPatternSets(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.sets))
+	for name := range s.sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writeJSON(w, http.StatusOK, map[string][]string{"patterns": names})
+}
+
+func (s *server) getPatternSet(w http.ResponseWriter, name string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set, found := s.sets[name]
+	if !found {
+		writeError(w, fmt.Errorf("pattern set %q: %w", name, errNotFound))
+		return
+	}
+	writeJSON(w, http.StatusOK, set)
+}
+
+// putPatternSet registers or replaces pattern set, expressions using it are recompiled
+// and replacement is rejected when any of them fails to compile.
+func (s *server) putPatternSet(w http.ResponseWriter, r *http.Request, name string) {
+	var set patternSet
+	if err := decodeJSON(r.Body, &set); err != nil {
+		writeError(w, err)
+		return
+	}
+	if set.Definitions == nil {
+		set.Definitions = make(map[string]string)
+	}
+	if err := grok.NewWithoutDefaultPatterns().AddPatterns(set.Definitions); err != nil {
+		writeError(w, fmt.Errorf("pattern set %q: %v: %w", name, err, errInvalid))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.sets[name]
+	s.sets[name] = set
+
+	recompiled := make(map[string]*grok.Grok)
+	for exprName, e := range s.expressions {
+		if !usesSet(e.spec, name) {
+			continue
+		}
+
+		g, err := s.compile(e.spec)
+		if err != nil {
+			if existed {
+				s.sets[name] = previous
+			} else {
+				delete(s.sets, name)
+			}
+			writeError(w, fmt.Errorf("expression %q: %v: %w", exprName, err, errInvalid))
+			return
+		}
+		recompiled[exprName] = g
+	}
+
+	for exprName, g := range recompiled {
+		e := s.expressions[exprName]
+		s.expressions[exprName] = &expression{spec: e.spec, g: g, metrics: e.metrics}
+	}
+
+	status := http.StatusCreated
+	if existed {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, set)
+}
+
+func (s *server) deletePatternSet(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.sets[name]; !found {
+		writeError(w, fmt.Errorf("pattern set %q: %w", name, errNotFound))
+		return
+	}
+	for exprName, e := range s.expressions {
+		if usesSet(e.spec, name) {
+			writeError(w, fmt.Errorf("pattern set %q is used by expression %q: %w", name, exprName, errConflict))
+			return
+		}
+	}
+
+	delete(s.sets, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) listExpressions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]expressionInfo, 0, len(s.expressions))
+	for name, e := range s.expressions {
+		infos = append(infos, newExpressionInfo(name, e.spec, e.g))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	writeJSON(w, http.StatusOK, map[string][]expressionInfo{"expressions": infos})
+}
+
+func (s *server) getExpression(w http.ResponseWriter, name string) {
+	e, err := s.expression(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newExpressionInfo(name, e.spec, e.g))
+}
+
+func (s *server) putExpression(w http.ResponseWriter, r *http.Request, name string) {
+	var spec expressionSpec
+	if err := decodeJSON(r.Body, &spec); err != nil {
+		writeError(w, err)
+		return
+	}
+	if spec.Expression == "" {
+		writeError(w, fmt.Errorf("expression not provided: %w", errInvalid))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, existed := s.expressions[name]
+	if !existed && len(s.expressions) >= s.limits.maxExpressions {
+		writeError(w, fmt.Errorf("at most %d expressions can be registered: %w", s.limits.maxExpressions, errConflict))
+		return
+	}
+
+	g, err := s.compile(spec)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.expressions[name] = &expression{spec: spec, g: g, metrics: &metrics{}}
+
+	status := http.StatusCreated
+	if existed {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, newExpressionInfo(name, spec, g))
+}
+
+func (s *server) deleteExpression(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.expressions[name]; !found {
+		writeError(w, fmt.Errorf("expression %q: %w", name, errNotFound))
+		return
+	}
+
+	delete(s.expressions, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getMetrics(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := make(map[string]metricsSnapshot, len(s.expressions))
+	for name, e := range s.expressions {
+		snapshots[name] = e.metrics.snapshot()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]map[string]metricsSnapshot{"expressions": snapshots})
+}
+
+func (s *server) parse(w http.ResponseWriter, r *http.Request, name string) {
+	e, err := s.expression(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var request parseRequest
+	if err := decodeJSON(r.Body, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(request.Line) > s.limits.maxLineLength {
+		writeError(w, fmt.Errorf("line longer than %d bytes: %w", s.limits.maxLineLength, errTooLarge))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e.parse(request.Line, r.URL.Query().Get("typed") == "true"))
+}
+
+// parseBatch parses NDJSON batch of parse requests and writes NDJSON of results in the same order.
+func (s *server) parseBatch(w http.ResponseWriter, r *http.Request, name string) {
+	e, err := s.expression(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	typed := r.URL.Query().Get("typed") == "true"
+
+	// whole batch is validated before any result is written so that errors can be reported with a status
+	var lines []string
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), int(s.limits.maxBodySize))
+	for number := 1; scanner.Scan(); number++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var request parseRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			writeError(w, fmt.Errorf("line %d: %v: %w", number, err, errInvalid))
+			return
+		}
+		if len(request.Line) > s.limits.maxLineLength {
+			writeError(w, fmt.Errorf("line %d: longer than %d bytes: %w", number, s.limits.maxLineLength, errTooLarge))
+			return
+		}
+
+		lines = append(lines, request.Line)
+		if len(lines) > s.limits.maxBatchLines {
+			writeError(w, fmt.Errorf("batch of more than %d lines: %w", s.limits.maxBatchLines, errTooLarge))
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		writeError(w, bodyError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	for _, line := range lines {
+		if err := encoder.Encode(e.parse(line, typed)); err != nil {
+			return
+		}
+	}
+}
+
+func (e *expression) parse(line string, typed bool) parseResult {
+	start := time.Now()
+	defer func() {
+		e.metrics.nanoseconds.Add(int64(time.Since(start)))
+	}()
+	e.metrics.lines.Add(1)
+
+	// the expression is run once, lines matched without any captured value are counted as unmatched
+	var fields map[string]interface{}
+	if typed {
+		values, err := e.g.ParseTypedString(line)
+		if err != nil {
+			// values are converted only for matched lines
+			e.metrics.matched.Add(1)
+			e.metrics.conversionFailures.Add(1)
+			return parseResult{Matched: true, Error: err.Error()}
+		}
+		fields = values
+	} else {
+		values, err := e.g.ParseString(line)
+		if err != nil {
+			e.metrics.matched.Add(1)
+			return parseResult{Matched: true, Error: err.Error()}
+		}
+		fields = make(map[string]interface{}, len(values))
+		for k, v := range values {
+			fields[k] = v
+		}
+	}
+
+	if len(fields) == 0 {
+		e.metrics.unmatched.Add(1)
+		return parseResult{}
+	}
+	e.metrics.matched.Add(1)
+	return parseResult{Matched: true, Fields: fields}
+}
+
+func (s *server) expression(name string) (*expression, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, found := s.expressions[name]
+	if !found {
+		return nil, fmt.Errorf("expression %q: %w", name, errNotFound)
+	}
+	return e, nil
+}
+
+// compile compiles expression with pattern sets it uses, caller holds the lock.
+func (s *server) compile(spec expressionSpec) (*grok.Grok, error) {
+	definitions := make([]map[string]string, 0, len(spec.Patterns))
+	for _, name := range spec.Patterns {
+		set, found := s.sets[name]
+		if !found {
+			return nil, fmt.Errorf("pattern set %q unknown: %w", name, errInvalid)
+		}
+		definitions = append(definitions, set.Definitions)
+	}
+
+	collection := patterns.ECSv1
+	if spec.Legacy {
+		collection = patterns.Legacy
+	}
+	g, err := grok.NewCompleteWithCollection(collection, definitions...)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, errInvalid)
+	}
+	if err := g.Compile(spec.Expression, !spec.AllCaptures); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, errInvalid)
+	}
+	return g, nil
+}
+
+func usesSet(spec expressionSpec, name string) bool {
+	for _, set := range spec.Patterns {
+		if set == name {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return bodyError(err)
+	}
+	return nil
+}
+
+func bodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("body larger than %d bytes: %w", maxBytesErr.Limit, errTooLarge)
+	}
+	return fmt.Errorf("%v: %w", err, errInvalid)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errConflict):
+		status = http.StatusConflict
+	case errors.Is(err, errTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, errInvalid):
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(v)
+}
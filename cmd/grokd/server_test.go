@@ -0,0 +1,207 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// do sends request to the server and returns status and body of the response.
+func do(t *testing.T, server *httptest.Server, method, path, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestServer(t *testing.T) {
+	server := httptest.NewServer(newServer(defaultLimits))
+	defer server.Close()
+
+	status, body := do(t, server, http.MethodGet, "/healthz", "")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"status":"ok"}`, body)
+
+	status, body = do(t, server, http.MethodPut, "/v1/patterns/app", `{"definitions": {"APP_ID": "[a-z]+-%{INT}"}}`)
+	require.Equal(t, http.StatusCreated, status, body)
+
+	status, body = do(t, server, http.MethodPut, "/v1/expressions/app", `{"expression": "id=%{APP_ID:app.id} took=%{NUMBER:took:float}", "patterns": ["app"]}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	require.JSONEq(t, `{"name":"app","expression":"id=%{APP_ID:app.id} took=%{NUMBER:took:float}","patterns":["app"],"fields":[{"name":"app.id"},{"name":"took","type":"float"}]}`, body)
+
+	status, body = do(t, server, http.MethodGet, "/v1/expressions", "")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"expressions":[{"name":"app","expression":"id=%{APP_ID:app.id} took=%{NUMBER:took:float}","patterns":["app"],"fields":[{"name":"app.id"},{"name":"took","type":"float"}]}]}`, body)
+
+	status, body = do(t, server, http.MethodPost, "/v1/expressions/app/parse", `{"line": "id=abc-1 took=0.5"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"matched":true,"fields":{"app.id":"abc-1","took":"0.5"}}`, body)
+
+	status, body = do(t, server, http.MethodPost, "/v1/expressions/app/parse?typed=true", `{"line": "id=abc-1 took=0.5"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"matched":true,"fields":{"app.id":"abc-1","took":0.5}}`, body)
+
+	status, body = do(t, server, http.MethodPost, "/v1/expressions/app/batch?typed=true", `{"line": "id=abc-1 took=1"}
+
+{"line": "something else"}
+{"line": "id=def-2 took=2"}
+`)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `{"matched":true,"fields":{"app.id":"abc-1","took":1}}
+{"matched":false}
+{"matched":true,"fields":{"app.id":"def-2","took":2}}
+`, body)
+
+	// hot replacement of pattern set recompiles expressions using it
+	status, body = do(t, server, http.MethodPut, "/v1/patterns/app", `{"definitions": {"APP_ID": "[A-Z]+-%{INT}"}}`)
+	require.Equal(t, http.StatusOK, status, body)
+	status, body = do(t, server, http.MethodPost, "/v1/expressions/app/parse", `{"line": "id=ABC-1 took=0.5"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"matched":true,"fields":{"app.id":"ABC-1","took":"0.5"}}`, body)
+
+	// replacement breaking an expression is rejected and previous set is kept
+	status, body = do(t, server, http.MethodPut, "/v1/patterns/app", `{"definitions": {"OTHER": "x"}}`)
+	require.Equal(t, http.StatusBadRequest, status, body)
+	require.Contains(t, body, `expression \"app\"`)
+	status, body = do(t, server, http.MethodGet, "/v1/patterns/app", "")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"definitions":{"APP_ID":"[A-Z]+-%{INT}"}}`, body)
+
+	status, body = do(t, server, http.MethodGet, "/v1/metrics", "")
+	require.Equal(t, http.StatusOK, status)
+	var metrics struct {
+		Expressions map[string]metricsSnapshot `json:"expressions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &metrics))
+	snapshot := metrics.Expressions["app"]
+	require.Equal(t, int64(6), snapshot.Lines)
+	require.Equal(t, int64(5), snapshot.Matched)
+	require.Equal(t, int64(1), snapshot.Unmatched)
+	require.Equal(t, int64(0), snapshot.ConversionFailures)
+	require.Greater(t, snapshot.ParseTime, int64(0))
+
+	// pattern set in use can't be deleted
+	status, _ = do(t, server, http.MethodDelete, "/v1/patterns/app", "")
+	require.Equal(t, http.StatusConflict, status)
+
+	status, _ = do(t, server, http.MethodDelete, "/v1/expressions/app", "")
+	require.Equal(t, http.StatusNoContent, status)
+	status, _ = do(t, server, http.MethodDelete, "/v1/patterns/app", "")
+	require.Equal(t, http.StatusNoContent, status)
+
+	status, body = do(t, server, http.MethodGet, "/v1/patterns", "")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"patterns":[]}`, body)
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := httptest.NewServer(newServer(limits{
+		maxBodySize:    200,
+		maxLineLength:  10,
+		maxBatchLines:  2,
+		maxExpressions: 2,
+	}))
+	defer server.Close()
+
+	status, _ := do(t, server, http.MethodPut, "/v1/expressions/word", `{"expression": "%{WORD:word:int}"}`)
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = do(t, server, http.MethodPut, "/v1/expressions/word", `{"expression": "%{WORD:word:int}", "all_captures": true}`)
+	require.Equal(t, http.StatusOK, status)
+
+	testCases := []struct {
+		Name   string
+		Method string
+		Path   string
+		Body   string
+		Status int
+	}{
+		{"unknown path", http.MethodGet, "/v2", "", http.StatusNotFound},
+		{"unknown expression", http.MethodPost, "/v1/expressions/missing/parse", `{"line": "a"}`, http.StatusNotFound},
+		{"unknown pattern set", http.MethodGet, "/v1/patterns/missing", "", http.StatusNotFound},
+		{"method not allowed", http.MethodPost, "/v1/patterns", "", http.StatusMethodNotAllowed},
+		{"invalid expression", http.MethodPut, "/v1/expressions/invalid", `{"expression": "%{MISSING}"}`, http.StatusBadRequest},
+		{"missing expression", http.MethodPut, "/v1/expressions/invalid", `{}`, http.StatusBadRequest},
+		{"expression with unknown set", http.MethodPut, "/v1/expressions/invalid", `{"expression": "%{INT}", "patterns": ["missing"]}`, http.StatusBadRequest},
+		{"unknown request field", http.MethodPut, "/v1/expressions/invalid", `{"expr": "%{INT}"}`, http.StatusBadRequest},
+		{"invalid pattern name", http.MethodPut, "/v1/patterns/invalid", `{"definitions": {"A:B": "x"}}`, http.StatusBadRequest},
+		{"body too large", http.MethodPost, "/v1/expressions/word/parse", `{"line": "` + strings.Repeat("a", 300) + `"}`, http.StatusRequestEntityTooLarge},
+		{"line too long", http.MethodPost, "/v1/expressions/word/parse", `{"line": "` + strings.Repeat("a", 11) + `"}`, http.StatusRequestEntityTooLarge},
+		{"batch too large", http.MethodPost, "/v1/expressions/word/batch", "{\"line\": \"a\"}\n{\"line\": \"b\"}\n{\"line\": \"c\"}\n", http.StatusRequestEntityTooLarge},
+		{"invalid batch", http.MethodPost, "/v1/expressions/word/batch", "{\"line\": \"a\"}\nnot json\n", http.StatusBadRequest},
+		{"too many expressions", http.MethodPut, "/v1/expressions/other", `{"expression": "%{INT}"}`, http.StatusCreated},
+		{"too many expressions", http.MethodPut, "/v1/expressions/third", `{"expression": "%{INT}"}`, http.StatusConflict},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			status, body := do(t, server, tt.Method, tt.Path, tt.Body)
+			require.Equal(t, tt.Status, status, body)
+		})
+	}
+
+	// conversion failures are reported per line
+	status, body := do(t, server, http.MethodPost, "/v1/expressions/word/parse?typed=true", `{"line": "abc"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"matched":true,"error":"strconv.Atoi: parsing \"abc\": invalid syntax"}`, body)
+}
+
+func TestServer_Concurrent(t *testing.T) {
+	server := httptest.NewServer(newServer(defaultLimits))
+	defer server.Close()
+
+	status, _ := do(t, server, http.MethodPut, "/v1/patterns/app", `{"definitions": {"APP_ID": "[a-z]+"}}`)
+	require.Equal(t, http.StatusCreated, status)
+	status, _ = do(t, server, http.MethodPut, "/v1/expressions/app", `{"expression": "%{APP_ID:id}", "patterns": ["app"]}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				status, body := do(t, server, http.MethodPost, "/v1/expressions/app/parse", `{"line": "abc"}`)
+				require.Equal(t, http.StatusOK, status)
+				require.JSONEq(t, `{"matched":true,"fields":{"id":"abc"}}`, body)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				status, _ := do(t, server, http.MethodPut, "/v1/patterns/app", `{"definitions": {"APP_ID": "[a-c]+"}}`)
+				require.Equal(t, http.StatusOK, status)
+			}
+		}()
+	}
+	wg.Wait()
+}
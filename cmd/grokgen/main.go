@@ -0,0 +1,151 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command grokgen generates Go source of grok expressions expanded ahead of time.
+//
+// It is meant to be used from a go:generate directive:
+//
+//	//go:generate go run github.com/elastic/go-grok/cmd/grokgen -o expressions_gen.go -e "CommonLog=%{HTTPD_COMMONLOG}"
+//
+// Every -e flag names a generated variable and its expression, the package name defaults
+// to the package of the file containing the directive. Expressions named by -struct flags are
+// generated as typed structs with parse functions instead:
+//
+//	//go:generate go run github.com/elastic/go-grok/cmd/grokgen -o access_gen.go -struct Access=AccessEntry -e "Access=%{HTTPD_COMBINEDLOG}"
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/codegen"
+	"github.com/elastic/go-grok/patterns"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// stringList is a flag which can be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("grokgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var expressions, structs, files stringList
+	fs.Var(&expressions, "e", "generated expression as Name=expression, can be repeated")
+	fs.Var(&structs, "struct", "generate expression as struct and parse function as Name=Type, can be repeated")
+	fs.Var(&files, "patterns", "pattern file or directory of pattern files, can be repeated")
+	legacy := fs.Bool("legacy", false, "use bundled patterns with legacy field names instead of ECS")
+	allCaptures := fs.Bool("all-captures", false, "capture unnamed groups as well")
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "package name of generated source, defaults to $GOPACKAGE set by go generate")
+	output := fs.String("o", "", "output file, standard output when not set")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if len(expressions) == 0 {
+		fmt.Fprintln(stderr, "grokgen: at least one expression required (-e)")
+		return 2
+	}
+
+	types := make(map[string]string)
+	for _, s := range structs {
+		name, typeName, found := strings.Cut(s, "=")
+		if !found {
+			fmt.Fprintf(stderr, "grokgen: expected Name=Type, got %q\n", s)
+			return 2
+		}
+		types[strings.TrimSpace(name)] = strings.TrimSpace(typeName)
+	}
+
+	opts := codegen.Options{Package: *pkg}
+	for _, e := range expressions {
+		name, expression, found := strings.Cut(e, "=")
+		if !found {
+			fmt.Fprintf(stderr, "grokgen: expected Name=expression, got %q\n", e)
+			return 2
+		}
+		name = strings.TrimSpace(name)
+		opts.Expressions = append(opts.Expressions, codegen.Expression{
+			Name:        name,
+			Type:        types[name],
+			Expression:  expression,
+			AllCaptures: *allCaptures,
+		})
+		delete(types, name)
+	}
+	for name := range types {
+		fmt.Fprintf(stderr, "grokgen: struct of unknown expression %q\n", name)
+		return 2
+	}
+
+	collection := patterns.ECSv1
+	if *legacy {
+		collection = patterns.Legacy
+	}
+	var definitions []map[string]string
+	for _, path := range files {
+		loaded, err := grok.LoadPatterns(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "grokgen: %v\n", err)
+			return 1
+		}
+		definitions = append(definitions, loaded)
+	}
+
+	g, err := grok.NewCompleteWithCollection(collection, definitions...)
+	if err != nil {
+		fmt.Fprintf(stderr, "grokgen: %v\n", err)
+		return 1
+	}
+
+	source, err := codegen.Generate(g, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "grokgen: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(source)
+	} else {
+		err = os.WriteFile(*output, source, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "grokgen: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	patterns := filepath.Join(dir, "custom")
+	require.NoError(t, os.WriteFile(patterns, []byte("DURATION %{NUMBER:duration:float}ms\n"), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-package", "gen", "-patterns", patterns, "-e", "Took=took %{DURATION}"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Contains(t, stdout.String(), "package gen\n")
+	require.Contains(t, stdout.String(), "var Took = grok.MustPrecompiled(")
+	require.Contains(t, stdout.String(), `{Name: "duration", Type: "float"},`)
+
+	stdout.Reset()
+	code = run([]string{"-package", "gen", "-patterns", patterns, "-struct", "Took=TookEntry", "-e", "Took=took %{DURATION}"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Contains(t, stdout.String(), "type TookEntry struct {")
+	require.Contains(t, stdout.String(), "func ParseTook(line string) (TookEntry, bool, error) {")
+
+	output := filepath.Join(dir, "gen.go")
+	stdout.Reset()
+	code = run([]string{"-package", "gen", "-patterns", patterns, "-o", output, "-e", "Took=took %{DURATION}"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Empty(t, stdout.String())
+	written, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.Contains(t, string(written), "var Took = grok.MustPrecompiled(")
+}
+
+func TestRunErrors(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Args []string
+		Code int
+	}{
+		{"no expressions", []string{"-package", "gen"}, 2},
+		{"missing name", []string{"-package", "gen", "-e", "%{WORD}"}, 2},
+		{"missing struct type", []string{"-package", "gen", "-struct", "A", "-e", "A=%{WORD}"}, 2},
+		{"struct of unknown expression", []string{"-package", "gen", "-struct", "B=T", "-e", "A=%{WORD}"}, 2},
+		{"unknown pattern", []string{"-package", "gen", "-e", "A=%{UNKNOWN}"}, 1},
+		{"missing package", []string{"-package", "", "-e", "A=%{WORD}"}, 1},
+		{"missing pattern file", []string{"-package", "gen", "-patterns", "/nonexistent", "-e", "A=%{WORD}"}, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, tc.Code, run(tc.Args, &stdout, &stderr))
+			require.NotEmpty(t, stderr.String())
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package codegen generates Go source of grok expressions expanded ahead of time.
+//
+// Generated variables are created by grok.MustPrecompiled from the expanded regular expression
+// and the table of captured fields with their type hints, so that no pattern definitions are
+// loaded nor expanded at runtime. Expressions can be generated as typed structs instead,
+// filled by generated parse functions. Source is usually generated by cmd/grokgen from a go:generate directive.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/elastic/go-grok"
+)
+
+// Expression is a grok expression to generate.
+type Expression struct {
+	// Name is the Go identifier of generated variable.
+	Name string
+	// Type is the name of generated struct type, when set the expression is generated as struct
+	// with fields typed according to type hints and function Parse<Name> filling it instead of a variable.
+	// Generated parse function does not depend on package grok.
+	Type string
+	// Expression is the grok expression, e.g. "%{HTTPD_COMMONLOG}".
+	Expression string
+	// AllCaptures captures unnamed groups as well, by default only named captures are.
+	AllCaptures bool
+}
+
+// Options configures generated source.
+type Options struct {
+	// Package is the name of package of generated source.
+	Package string
+	// Expressions are generated in the given order.
+	Expressions []Expression
+}
+
+type generatedExpression struct {
+	Name       string
+	Type       string
+	Expression string
+	Regex      string
+	Fields     []grok.Field
+	Struct     *typedStruct
+}
+
+var source = template.Must(template.New("source").Funcs(template.FuncMap{
+	"literal": literal,
+	"quote":   strconv.Quote,
+}).Parse(`// Code generated by grokgen. DO NOT EDIT.
+
+package {{.Package}}
+
+{{if eq (len .Imports) 1 -}}
+import {{quote (index .Imports 0)}}
+{{- else -}}
+import (
+{{- range .Imports}}
+	{{if .}}{{quote .}}{{end}}
+{{- end}}
+)
+{{- end}}
+{{range .Expressions}}
+{{- if .Struct}}
+// {{.Type}} holds fields captured by grok expression:
+//
+//	{{.Expression}}
+{{.Struct.Declaration}}
+{{- else}}
+// {{.Name}} is precompiled from grok expression:
+//
+//	{{.Expression}}
+var {{.Name}} = grok.MustPrecompiled(grok.Precompiled{
+	Regex: {{literal .Regex}},
+	Fields: []grok.Field{
+	{{- range .Fields}}
+		{Name: {{quote .Name}}{{if .Type}}, Type: {{quote .Type}}{{end}}},
+	{{- end}}
+	},
+})
+{{- end}}
+{{end}}`))
+
+// Generate returns formatted Go source of expressions expanded using patterns known to g.
+func Generate(g *grok.Grok, opts Options) ([]byte, error) {
+	if !token.IsIdentifier(opts.Package) {
+		return nil, fmt.Errorf("invalid package name %q", opts.Package)
+	}
+
+	seen := make(map[string]bool)
+	imports := make(map[string]bool)
+	expressions := make([]generatedExpression, 0, len(opts.Expressions))
+	for _, e := range opts.Expressions {
+		if !token.IsIdentifier(e.Name) {
+			return nil, fmt.Errorf("invalid name %q of expression %q", e.Name, e.Expression)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("duplicate name %q", e.Name)
+		}
+		seen[e.Name] = true
+		if e.Type != "" {
+			if !token.IsIdentifier(e.Type) || seen[e.Type] {
+				return nil, fmt.Errorf("invalid type name %q of expression %s", e.Type, e.Name)
+			}
+			seen[e.Type] = true
+		}
+
+		expansion, err := g.Expand(e.Expression, !e.AllCaptures)
+		if err != nil {
+			return nil, fmt.Errorf("expression %s: %w", e.Name, err)
+		}
+
+		// expression is placed in a comment
+		comment := e.Expression
+		if strings.ContainsAny(comment, "\r\n") {
+			comment = strconv.Quote(comment)
+		}
+
+		generated := generatedExpression{
+			Name:       e.Name,
+			Type:       e.Type,
+			Expression: comment,
+			Regex:      expansion.Regex,
+			Fields:     expansion.Fields,
+		}
+		if e.Type == "" {
+			imports["github.com/elastic/go-grok"] = true
+		} else {
+			if generated.Struct, err = generateStruct(e.Name, e.Type, expansion); err != nil {
+				return nil, fmt.Errorf("expression %s: %w", e.Name, err)
+			}
+			imports["regexp"] = true
+			if generated.Struct.Typed {
+				imports["fmt"], imports["strconv"] = true, true
+			}
+		}
+		expressions = append(expressions, generated)
+	}
+
+	// standard library imports are separated from others by an empty line
+	var standard, other []string
+	for path := range imports {
+		if strings.Contains(path, ".") {
+			other = append(other, path)
+		} else {
+			standard = append(standard, path)
+		}
+	}
+	sort.Strings(standard)
+	sort.Strings(other)
+	sortedImports := standard
+	if len(standard) > 0 && len(other) > 0 {
+		sortedImports = append(sortedImports, "")
+	}
+	sortedImports = append(sortedImports, other...)
+
+	var buf bytes.Buffer
+	err := source.Execute(&buf, struct {
+		Package     string
+		Imports     []string
+		Expressions []generatedExpression
+	}{opts.Package, sortedImports, expressions})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// literal returns s as a raw string literal when possible, regular expressions are full of backslashes.
+func literal(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codegen_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/codegen"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	// the same expressions as in the go:generate directive of package example
+	source, err := codegen.Generate(g, codegen.Options{
+		Package: "example",
+		Expressions: []codegen.Expression{
+			{Name: "CommonLog", Expression: "%{HTTPD_COMMONLOG}"},
+			{Name: "Request", Expression: "%{WORD:method} %{URIPATHPARAM:path} took %{NUMBER:duration:float}ms"},
+			{Name: "CombinedLog", Type: "CombinedLogEntry", Expression: "%{HTTPD_COMBINEDLOG}"},
+		},
+	})
+	require.NoError(t, err)
+
+	golden, err := os.ReadFile("example/expressions_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(golden), string(source), "run go generate ./codegen/example to update")
+}
+
+func TestGenerate(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	source, err := codegen.Generate(g, codegen.Options{
+		Package: "custom",
+		Expressions: []codegen.Expression{
+			{Name: "word", Expression: "(a|b)`%{WORD:w}\n", AllCaptures: true},
+		},
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(source), "package custom\n")
+	// expression with a newline is quoted in comment, regex with backquote in an interpreted literal
+	require.Contains(t, string(source), "//\t\"(a|b)`%{WORD:w}\\n\"\n")
+	require.Contains(t, string(source), "Regex: \"(a|b)`(?P<w>\\\\b\\\\w+\\\\b)\\n\",")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name    string
+		Options codegen.Options
+	}{
+		{"invalid package", codegen.Options{Package: "my-package", Expressions: []codegen.Expression{{Name: "A", Expression: "%{WORD}"}}}},
+		{"invalid name", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "1A", Expression: "%{WORD}"}}}},
+		{"duplicate name", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Expression: "%{WORD}"}, {Name: "A", Expression: "%{INT}"}}}},
+		{"unknown pattern", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Expression: "%{UNKNOWN}"}}}},
+		{"invalid type name", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Type: "a-b", Expression: "%{WORD}"}}}},
+		{"type named as expression", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Type: "A", Expression: "%{WORD}"}}}},
+		{"unsupported type", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Type: "T", Expression: "%{WORD:w:date}"}}}},
+		{"value and nested field", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Type: "T", Expression: "%{WORD:url} %{WORD:url.path}"}}}},
+		{"nested field and value", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Type: "T", Expression: "%{WORD:url.path} %{WORD:url}"}}}},
+		{"same Go name", codegen.Options{Package: "p", Expressions: []codegen.Expression{{Name: "A", Type: "T", Expression: "%{WORD:user_name} %{WORD:userName}"}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := codegen.Generate(g, tc.Options)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGenerateStruct(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	source, err := codegen.Generate(g, codegen.Options{
+		Package: "custom",
+		Expressions: []codegen.Expression{
+			{Name: "Event", Type: "EventEntry", Expression: "%{IP:source.ip} %{WORD:http_method} %{WORD:event.id} %{WORD:2fa}"},
+		},
+	})
+	require.NoError(t, err)
+
+	// struct without typed fields does not use conversions nor package grok
+	require.Contains(t, string(source), "import \"regexp\"\n")
+	require.Contains(t, string(source), "var eventRegexp = regexp.MustCompile(")
+	require.Contains(t, string(source), "func ParseEvent(line string) (EventEntry, bool, error) {")
+	for _, field := range []string{
+		"IP string `grok:\"source.ip\"`",
+		"HTTPMethod string `grok:\"http_method\"`",
+		"ID string `grok:\"event.id\"`",
+		"F2fa string `grok:\"2fa\"`",
+	} {
+		require.Contains(t, string(source), field)
+	}
+}
@@ -0,0 +1,22 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package example contains expressions generated by grokgen, it is regenerated by go generate
+// and serves as the golden output of package codegen.
+package example
+
+//go:generate go run ../../cmd/grokgen -o expressions_gen.go -e CommonLog=%{HTTPD_COMMONLOG} -e "Request=%{WORD:method} %{URIPATHPARAM:path} took %{NUMBER:duration:float}ms" -struct CombinedLog=CombinedLogEntry -e CombinedLog=%{HTTPD_COMBINEDLOG}
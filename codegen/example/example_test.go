@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package example_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/codegen/example"
+)
+
+func TestPrecompiledMatchesCompiled(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Precompiled *grok.Grok
+		Expression  string
+		Line        string
+	}{
+		{"common log", example.CommonLog, "%{HTTPD_COMMONLOG}", `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`},
+		{"request", example.Request, "%{WORD:method} %{URIPATHPARAM:path} took %{NUMBER:duration:float}ms", "GET /index.html?q=1 took 12.5ms"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			g, err := grok.NewComplete()
+			require.NoError(t, err)
+			require.NoError(t, g.Compile(tc.Expression, true))
+
+			require.Equal(t, g.Fields(), tc.Precompiled.Fields())
+
+			expected, err := g.ParseTypedString(tc.Line)
+			require.NoError(t, err)
+			actual, err := tc.Precompiled.ParseTypedString(tc.Line)
+			require.NoError(t, err)
+			require.NotEmpty(t, actual)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParseCombinedLog(t *testing.T) {
+	entry, matched, err := example.ParseCombinedLog(`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`)
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	var expected example.CombinedLogEntry
+	expected.Source.Address = "127.0.0.1"
+	expected.User.Name = "frank"
+	expected.Timestamp = "10/Oct/2000:13:55:36 -0700"
+	expected.HTTP.Request.Method = "GET"
+	expected.URL.Original = "/apache_pb.gif"
+	expected.HTTP.Version = "1.0"
+	expected.HTTP.Response.StatusCode = 200
+	expected.HTTP.Response.Body.Size = 2326
+	expected.HTTP.Request.Referrer = "http://www.example.com/start.html"
+	expected.UserAgent.Original = "Mozilla/4.08"
+	require.Equal(t, expected, entry)
+
+	// optional parts not captured leave zero values
+	entry, matched, err = example.ParseCombinedLog(`127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 304 - "-" "curl"`)
+	require.NoError(t, err)
+	require.True(t, matched)
+	require.Equal(t, "", entry.User.Name)
+	require.Equal(t, int64(0), entry.HTTP.Response.Body.Size)
+	require.Equal(t, 304, entry.HTTP.Response.StatusCode)
+
+	_, matched, err = example.ParseCombinedLog("not an access log")
+	require.NoError(t, err)
+	require.False(t, matched)
+}
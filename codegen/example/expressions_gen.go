@@ -0,0 +1,136 @@
+// Code generated by grokgen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/elastic/go-grok"
+)
+
+// CommonLog is precompiled from grok expression:
+//
+//	%{HTTPD_COMMONLOG}
+var CommonLog = grok.MustPrecompiled(grok.Precompiled{
+	Regex: `(?:(?P<source___address>(?:(?:(?:(?:(?:(?:(?:[0-9A-Fa-f]{1,4}:){7}(?:[0-9A-Fa-f]{1,4}|:))|(?:(?:[0-9A-Fa-f]{1,4}:){6}(?::[0-9A-Fa-f]{1,4}|(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})|:))|(?:(?:[0-9A-Fa-f]{1,4}:){5}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,2})|:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})|:))|(?:(?:[0-9A-Fa-f]{1,4}:){4}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,3})|(?:(?::[0-9A-Fa-f]{1,4})?:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?:(?:[0-9A-Fa-f]{1,4}:){3}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,4})|(?:(?::[0-9A-Fa-f]{1,4}){0,2}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?:(?:[0-9A-Fa-f]{1,4}:){2}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,5})|(?:(?::[0-9A-Fa-f]{1,4}){0,3}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?:(?:[0-9A-Fa-f]{1,4}:){1}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,6})|(?:(?::[0-9A-Fa-f]{1,4}){0,4}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?::(?:(?:(?::[0-9A-Fa-f]{1,4}){1,7})|(?:(?::[0-9A-Fa-f]{1,4}){0,5}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:)))(?:%.+)?)|(?:(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?))))|(?:\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)))) (?:-|(?P<apache___access___user___identity>(?:(?:[a-zA-Z][a-zA-Z0-9_.+-=:]+)@(?:\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)))|(?:(?:[a-zA-Z0-9._-]+)))) (?:-|(?P<user___name>(?:(?:[a-zA-Z][a-zA-Z0-9_.+-=:]+)@(?:\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)))|(?:(?:[a-zA-Z0-9._-]+)))) \[(?P<timestamp>(?:(?:(?:0[1-9])|(?:[12][0-9])|(?:3[01])|[1-9]))/(?:\b(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|Jun(?:e)?|Jul(?:y)?|Aug(?:ust)?|Sep(?:tember)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\b)/(?:(?:\d\d){1,2}):(?:(?:(?:2[0123]|[01]?[0-9])):(?:(?:[0-5][0-9]))(?::(?:(?:(?:[0-5][0-9]|60)(?:[:.,][0-9]+)?)))?) (?:(?:[+-]?(?:[0-9]+))))\] "(?:(?P<http___request___method>\b\w+\b) (?P<url___original>\S+)(?: HTTP/(?P<http___version>(?:(?:(?:[+-]?(?:[0-9]+(?:\.[0-9]+)?)|\.[0-9]+)))))?|(?:.*?))" (?:-|(?P<http___response___status_code>(?:[+-]?(?:[0-9]+)))) (?:-|(?P<http___response___body___size>(?:[+-]?(?:[0-9]+)))))`,
+	Fields: []grok.Field{
+		{Name: "source.address"},
+		{Name: "apache.access.user.identity"},
+		{Name: "user.name"},
+		{Name: "timestamp"},
+		{Name: "http.request.method"},
+		{Name: "url.original"},
+		{Name: "http.version"},
+		{Name: "http.response.status_code", Type: "int"},
+		{Name: "http.response.body.size", Type: "long"},
+	},
+})
+
+// Request is precompiled from grok expression:
+//
+//	%{WORD:method} %{URIPATHPARAM:path} took %{NUMBER:duration:float}ms
+var Request = grok.MustPrecompiled(grok.Precompiled{
+	Regex: `(?P<method>\b\w+\b) (?P<path>(?:(?:/[A-Za-z0-9$.+!*'(){},~:;=@#%&_\-]+)+)(?:\?(?:[A-Za-z0-9$.+!*'|(){},~@#%&/=:;_?\-\[\]<>]*))?) took (?P<duration>(?:(?:(?:[+-]?(?:[0-9]+(?:\.[0-9]+)?)|\.[0-9]+))))ms`,
+	Fields: []grok.Field{
+		{Name: "method"},
+		{Name: "path"},
+		{Name: "duration", Type: "float"},
+	},
+})
+
+// CombinedLogEntry holds fields captured by grok expression:
+//
+//	%{HTTPD_COMBINEDLOG}
+type CombinedLogEntry struct {
+	Source struct {
+		Address string `grok:"source.address"`
+	}
+	Apache struct {
+		Access struct {
+			User struct {
+				Identity string `grok:"apache.access.user.identity"`
+			}
+		}
+	}
+	User struct {
+		Name string `grok:"user.name"`
+	}
+	Timestamp string `grok:"timestamp"`
+	HTTP      struct {
+		Request struct {
+			Method   string `grok:"http.request.method"`
+			Referrer string `grok:"http.request.referrer"`
+		}
+		Version  string `grok:"http.version"`
+		Response struct {
+			StatusCode int `grok:"http.response.status_code"`
+			Body       struct {
+				Size int64 `grok:"http.response.body.size"`
+			}
+		}
+	}
+	URL struct {
+		Original string `grok:"url.original"`
+	}
+	UserAgent struct {
+		Original string `grok:"user_agent.original"`
+	}
+}
+
+var combinedLogRegexp = regexp.MustCompile(`(?:(?:(?P<source___address>(?:(?:(?:(?:(?:(?:(?:[0-9A-Fa-f]{1,4}:){7}(?:[0-9A-Fa-f]{1,4}|:))|(?:(?:[0-9A-Fa-f]{1,4}:){6}(?::[0-9A-Fa-f]{1,4}|(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})|:))|(?:(?:[0-9A-Fa-f]{1,4}:){5}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,2})|:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3})|:))|(?:(?:[0-9A-Fa-f]{1,4}:){4}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,3})|(?:(?::[0-9A-Fa-f]{1,4})?:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?:(?:[0-9A-Fa-f]{1,4}:){3}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,4})|(?:(?::[0-9A-Fa-f]{1,4}){0,2}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?:(?:[0-9A-Fa-f]{1,4}:){2}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,5})|(?:(?::[0-9A-Fa-f]{1,4}){0,3}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?:(?:[0-9A-Fa-f]{1,4}:){1}(?:(?:(?::[0-9A-Fa-f]{1,4}){1,6})|(?:(?::[0-9A-Fa-f]{1,4}){0,4}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:))|(?::(?:(?:(?::[0-9A-Fa-f]{1,4}){1,7})|(?:(?::[0-9A-Fa-f]{1,4}){0,5}:(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)(?:\.(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)){3}))|:)))(?:%.+)?)|(?:(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?))))|(?:\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)))) (?:-|(?P<apache___access___user___identity>(?:(?:[a-zA-Z][a-zA-Z0-9_.+-=:]+)@(?:\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)))|(?:(?:[a-zA-Z0-9._-]+)))) (?:-|(?P<user___name>(?:(?:[a-zA-Z][a-zA-Z0-9_.+-=:]+)@(?:\b(?:[0-9A-Za-z][0-9A-Za-z-]{0,62})(?:\.(?:[0-9A-Za-z][0-9A-Za-z-]{0,62}))*(?:\.?|\b)))|(?:(?:[a-zA-Z0-9._-]+)))) \[(?P<timestamp>(?:(?:(?:0[1-9])|(?:[12][0-9])|(?:3[01])|[1-9]))/(?:\b(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|Jun(?:e)?|Jul(?:y)?|Aug(?:ust)?|Sep(?:tember)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\b)/(?:(?:\d\d){1,2}):(?:(?:(?:2[0123]|[01]?[0-9])):(?:(?:[0-5][0-9]))(?::(?:(?:(?:[0-5][0-9]|60)(?:[:.,][0-9]+)?)))?) (?:(?:[+-]?(?:[0-9]+))))\] "(?:(?P<http___request___method>\b\w+\b) (?P<url___original>\S+)(?: HTTP/(?P<http___version>(?:(?:(?:[+-]?(?:[0-9]+(?:\.[0-9]+)?)|\.[0-9]+)))))?|(?:.*?))" (?:-|(?P<http___response___status_code>(?:[+-]?(?:[0-9]+)))) (?:-|(?P<http___response___body___size>(?:[+-]?(?:[0-9]+))))) "(?:-|(?P<http___request___referrer>.*?))" "(?:-|(?P<user_agent___original>.*?))")`)
+
+// ParseCombinedLog parses line matching CombinedLog into CombinedLogEntry.
+// The second result reports whether line matched, values not convertible to types of fields result in an error.
+func ParseCombinedLog(line string) (CombinedLogEntry, bool, error) {
+	var result CombinedLogEntry
+	m := combinedLogRegexp.FindStringSubmatchIndex(line)
+	if m == nil {
+		return result, false, nil
+	}
+	if m[2] < m[3] {
+		result.Source.Address = line[m[2]:m[3]]
+	}
+	if m[4] < m[5] {
+		result.Apache.Access.User.Identity = line[m[4]:m[5]]
+	}
+	if m[6] < m[7] {
+		result.User.Name = line[m[6]:m[7]]
+	}
+	if m[8] < m[9] {
+		result.Timestamp = line[m[8]:m[9]]
+	}
+	if m[10] < m[11] {
+		result.HTTP.Request.Method = line[m[10]:m[11]]
+	}
+	if m[12] < m[13] {
+		result.URL.Original = line[m[12]:m[13]]
+	}
+	if m[14] < m[15] {
+		result.HTTP.Version = line[m[14]:m[15]]
+	}
+	if m[16] < m[17] {
+		s := line[m[16]:m[17]]
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return result, true, fmt.Errorf("field http.response.status_code: %w", err)
+		}
+		result.HTTP.Response.StatusCode = v
+	}
+	if m[18] < m[19] {
+		s := line[m[18]:m[19]]
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return result, true, fmt.Errorf("field http.response.body.size: %w", err)
+		}
+		result.HTTP.Response.Body.Size = v
+	}
+	if m[20] < m[21] {
+		result.HTTP.Request.Referrer = line[m[20]:m[21]]
+	}
+	if m[22] < m[23] {
+		result.UserAgent.Original = line[m[22]:m[23]]
+	}
+	return result, true, nil
+}
@@ -0,0 +1,223 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/elastic/go-grok"
+)
+
+// goTypes are Go types of fields by type hint, fields without hint are strings.
+var goTypes = map[string]string{
+	"":        "string",
+	"string":  "string",
+	"int":     "int",
+	"long":    "int64",
+	"float":   "float64",
+	"double":  "float64",
+	"bool":    "bool",
+	"boolean": "bool",
+}
+
+// conversions are expressions converting captured text held by s to Go type of hint.
+var conversions = map[string]string{
+	"int":     "strconv.Atoi(s)",
+	"long":    "strconv.ParseInt(s, 10, 64)",
+	"float":   "strconv.ParseFloat(s, 64)",
+	"double":  "strconv.ParseFloat(s, 64)",
+	"bool":    "strconv.ParseBool(s)",
+	"boolean": "strconv.ParseBool(s)",
+}
+
+// initialisms are name segments written in upper case as Go naming conventions suggest.
+var initialisms = map[string]bool{
+	"api": true, "dns": true, "gid": true, "http": true, "id": true, "ip": true, "json": true, "mac": true,
+	"os": true, "pid": true, "tcp": true, "tls": true, "ssl": true, "udp": true, "uid": true, "uri": true, "url": true,
+}
+
+// structNode is a field of generated struct, either a value of captured field or a nested struct of dotted names.
+type structNode struct {
+	goName   string
+	field    string
+	hint     string
+	children []*structNode
+}
+
+// typedStruct is struct type and parse function generated for an expression.
+type typedStruct struct {
+	// Declaration is source of struct type without doc comment, regular expression and parse function.
+	Declaration string
+	// Typed reports whether conversion of values is used.
+	Typed bool
+}
+
+// generateStruct returns source of struct named typeName mirroring fields of expansion,
+// nested structs are generated for dotted names, and function Parse<name> filling it using submatch indices.
+func generateStruct(name, typeName string, expansion *grok.Expansion) (*typedStruct, error) {
+	re, err := regexp.Compile(expansion.Regex)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &structNode{}
+	nodes := make(map[string]*structNode)
+	for _, field := range expansion.Fields {
+		if _, found := goTypes[field.Type]; !found {
+			return nil, fmt.Errorf("field %q: unsupported type %q", field.Name, field.Type)
+		}
+		node, err := root.add(field.Name, strings.Split(field.Name, "."), field.Type)
+		if err != nil {
+			return nil, err
+		}
+		nodes[field.Name] = node
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "type %s ", typeName)
+	root.writeType(&sb)
+	sb.WriteString("\n\n")
+
+	regexpName := unexported(name) + "Regexp"
+	fmt.Fprintf(&sb, "var %s = regexp.MustCompile(%s)\n\n", regexpName, literal(expansion.Regex))
+
+	fmt.Fprintf(&sb, "// Parse%s parses line matching %s into %s.\n", name, name, typeName)
+	sb.WriteString("// The second result reports whether line matched, values not convertible to types of fields result in an error.\n")
+	fmt.Fprintf(&sb, "func Parse%s(line string) (%s, bool, error) {\n", name, typeName)
+	fmt.Fprintf(&sb, "var result %s\n", typeName)
+	fmt.Fprintf(&sb, "m := %s.FindStringSubmatchIndex(line)\n", regexpName)
+	sb.WriteString("if m == nil {\nreturn result, false, nil\n}\n")
+
+	typed := false
+	// groups of the same field are assigned in order, the last non empty capture wins as in grok.ParseTyped
+	for i, group := range re.SubexpNames() {
+		if group == "" {
+			continue
+		}
+		// capture groups use "___" in place of dots of field names
+		node := nodes[strings.ReplaceAll(group, "___", ".")]
+		if node == nil {
+			continue
+		}
+
+		start, end := 2*i, 2*i+1
+		fmt.Fprintf(&sb, "if m[%d] < m[%d] {\n", start, end)
+		conversion, found := conversions[node.hint]
+		if !found {
+			fmt.Fprintf(&sb, "result.%s = line[m[%d]:m[%d]]\n}\n", node.goName, start, end)
+			continue
+		}
+
+		typed = true
+		fmt.Fprintf(&sb, "s := line[m[%d]:m[%d]]\n", start, end)
+		fmt.Fprintf(&sb, "v, err := %s\n", conversion)
+		fmt.Fprintf(&sb, "if err != nil {\nreturn result, true, fmt.Errorf(\"field %s: %%w\", err)\n}\n", node.field)
+		fmt.Fprintf(&sb, "result.%s = v\n}\n", node.goName)
+	}
+	sb.WriteString("return result, true, nil\n}\n")
+
+	return &typedStruct{Declaration: sb.String(), Typed: typed}, nil
+}
+
+// add adds field with remaining segments of its name and returns the value node,
+// goName of returned node is the selector path from the root.
+func (n *structNode) add(field string, segments []string, hint string) (*structNode, error) {
+	goName := goIdentifier(segments[0])
+
+	var child *structNode
+	for _, c := range n.children {
+		if c.goName == goName {
+			child = c
+			break
+		}
+	}
+
+	last := len(segments) == 1
+	switch {
+	case child == nil:
+		child = &structNode{goName: goName}
+		n.children = append(n.children, child)
+		if last {
+			child.field, child.hint = field, hint
+			return &structNode{goName: goName, field: field, hint: hint}, nil
+		}
+	case last || child.field != "":
+		existing := child.field
+		if existing == "" {
+			existing = "fields nested in it"
+		}
+		return nil, fmt.Errorf("field %q conflicts with %s as Go field %s", field, existing, goName)
+	}
+
+	leaf, err := child.add(field, segments[1:], hint)
+	if err != nil {
+		return nil, err
+	}
+	return &structNode{goName: goName + "." + leaf.goName, field: leaf.field, hint: leaf.hint}, nil
+}
+
+func (n *structNode) writeType(sb *strings.Builder) {
+	sb.WriteString("struct {\n")
+	for _, child := range n.children {
+		sb.WriteString(child.goName + " ")
+		if len(child.children) > 0 {
+			child.writeType(sb)
+			sb.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(sb, "%s `grok:%q`\n", goTypes[child.hint], child.field)
+	}
+	sb.WriteString("}")
+}
+
+// goIdentifier converts segment of field name to exported Go identifier, e.g. "status_code" to StatusCode.
+func goIdentifier(segment string) string {
+	words := strings.FieldsFunc(segment, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			sb.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+
+	name := sb.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "F" + name
+	}
+	return name
+}
+
+func unexported(name string) string {
+	runes := []rune(name)
+	prefix := 1
+	// leading initialisms are lowered as a whole, e.g. HTTPLog to httpLog
+	for prefix < len(runes) && unicode.IsUpper(runes[prefix]) && (prefix+1 == len(runes) || unicode.IsUpper(runes[prefix+1])) {
+		prefix++
+	}
+	return strings.ToLower(string(runes[:prefix])) + string(runes[prefix:])
+}
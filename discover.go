@@ -0,0 +1,188 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultDiscoverPriority lists patterns tried by Discover, most specific first.
+var DefaultDiscoverPriority = []string{
+	"TIMESTAMP_ISO8601",
+	"HTTPDATE",
+	"DATESTAMP_RFC2822",
+	"SYSLOGTIMESTAMP",
+	"DATESTAMP",
+	"UUID",
+	"URI",
+	"EMAILADDRESS",
+	"MAC",
+	"IPV6",
+	"IPV4",
+	"UNIXPATH",
+	"LOGLEVEL",
+	"QUOTEDSTRING",
+	"NUMBER",
+}
+
+// DiscoverOptions configures Discover.
+type DiscoverOptions struct {
+	// Priority lists names of patterns tried in order, parts of sample matched by earlier patterns
+	// are not considered by later ones. DefaultDiscoverPriority is used when empty.
+	Priority []string
+	// Fields names captured fields after lower cased pattern names, e.g %{IPV4:ipv4} and %{IPV4:ipv4_2}.
+	// References are not captured otherwise.
+	Fields bool
+}
+
+// part of discovered sample, either literal text or text matched by a pattern
+type discoveredPart struct {
+	text    string
+	pattern string
+	// offset of text within sample
+	offset int
+}
+
+// Discover suggests grok expression matching sample.
+// Sample is scanned for patterns listed in options in order of priority, each match not splitting a word
+// is replaced by a reference to the pattern while the rest of sample is kept as escaped literal text.
+// A replacement is kept only when the resulting expression still matches the whole sample,
+// so returned expression always matches it.
+func (grok *Grok) Discover(sample string, opts DiscoverOptions) (string, error) {
+	priority := opts.Priority
+	if len(priority) == 0 {
+		priority = DefaultDiscoverPriority
+	}
+
+	parts := []discoveredPart{{text: sample}}
+	for _, name := range priority {
+		expression, _, err := grok.expand("%{"+name+"}", true, nil)
+		if err != nil {
+			return "", err
+		}
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return "", err
+		}
+
+		for i := 0; i < len(parts); i++ {
+			if parts[i].pattern != "" {
+				continue
+			}
+
+			for _, loc := range re.FindAllStringIndex(parts[i].text, -1) {
+				if !onWordBoundaries(sample, parts[i].offset+loc[0], parts[i].offset+loc[1]) {
+					continue
+				}
+
+				candidate := splitPart(parts, i, loc, name)
+				if !grok.discoveredMatches(candidate, sample, opts.Fields) {
+					continue
+				}
+
+				// continue with the remaining text following the replaced one
+				parts = candidate
+				if loc[0] > 0 {
+					i++
+				}
+				break
+			}
+		}
+	}
+
+	expression := renderDiscovered(parts, opts.Fields)
+	if !grok.discoveredMatches(parts, sample, opts.Fields) {
+		return "", fmt.Errorf("discovered expression %q does not match sample: %w", expression, ErrParseFailure)
+	}
+
+	return expression, nil
+}
+
+func (grok *Grok) discoveredMatches(parts []discoveredPart, sample string, fields bool) bool {
+	expression, _, err := grok.expand(renderDiscovered(parts, fields), true, nil)
+	if err != nil {
+		return false
+	}
+
+	re, err := regexp.Compile("^(?:" + expression + ")$")
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(sample)
+}
+
+// splitPart returns copy of parts with literal part i split around loc matched by pattern.
+func splitPart(parts []discoveredPart, i int, loc []int, pattern string) []discoveredPart {
+	text, offset := parts[i].text, parts[i].offset
+
+	split := make([]discoveredPart, 0, len(parts)+2)
+	split = append(split, parts[:i]...)
+	if loc[0] > 0 {
+		split = append(split, discoveredPart{text: text[:loc[0]], offset: offset})
+	}
+	split = append(split, discoveredPart{text: text[loc[0]:loc[1]], pattern: pattern, offset: offset + loc[0]})
+	if loc[1] < len(text) {
+		split = append(split, discoveredPart{text: text[loc[1]:], offset: offset + loc[1]})
+	}
+	return append(split, parts[i+1:]...)
+}
+
+func renderDiscovered(parts []discoveredPart, fields bool) string {
+	var sb strings.Builder
+	used := make(map[string]int)
+
+	for _, part := range parts {
+		if part.pattern == "" {
+			sb.WriteString(regexp.QuoteMeta(part.text))
+			continue
+		}
+
+		if !fields {
+			sb.WriteString("%{" + part.pattern + "}")
+			continue
+		}
+
+		field := strings.ToLower(part.pattern)
+		used[field]++
+		if n := used[field]; n > 1 {
+			field += "_" + strconv.Itoa(n)
+		}
+		sb.WriteString("%{" + part.pattern + ":" + field + "}")
+	}
+
+	return sb.String()
+}
+
+// onWordBoundaries reports whether text[start:end] is not empty and is not preceded or followed by a word character.
+func onWordBoundaries(text string, start, end int) bool {
+	if start == end {
+		return false
+	}
+
+	startOk := start == 0 || !isWordChar(text[start-1])
+	endOk := end == len(text) || !isWordChar(text[end])
+	return startOk && endOk
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
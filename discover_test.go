@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestDiscover(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Sample   string
+		Options  grok.DiscoverOptions
+		Expected string
+	}{
+		{
+			"application log",
+			`2024-01-02T03:04:05.123Z INFO [main] user=alice ip=10.0.0.1 took 12.5ms msg="hello world"`,
+			grok.DiscoverOptions{},
+			`%{TIMESTAMP_ISO8601} %{LOGLEVEL} \[main\] user=alice ip=%{IPV4} took 12\.5ms msg=%{QUOTEDSTRING}`,
+		},
+		{
+			"access log with fields",
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			grok.DiscoverOptions{Fields: true},
+			`%{IPV4:ipv4} - frank \[%{HTTPDATE:httpdate}\] "GET %{UNIXPATH:unixpath} HTTP/%{NUMBER:number}" %{NUMBER:number_2} %{NUMBER:number_3}`,
+		},
+		{
+			"words are not split",
+			`host lb-001 pid[42] uuid=123e4567-e89b-12d3-a456-426614174000`,
+			grok.DiscoverOptions{},
+			`host lb-001 pid\[%{NUMBER}\] uuid=%{UUID}`,
+		},
+		{
+			"custom priority",
+			`took 12 ms at 10.0.0.1`,
+			grok.DiscoverOptions{Priority: []string{"IPV4", "INT"}},
+			`took %{INT} ms at %{IPV4}`,
+		},
+		{
+			"earlier pattern wins",
+			`took 12 ms at 10.0.0.1`,
+			grok.DiscoverOptions{Priority: []string{"INT", "IPV4"}},
+			`took %{INT} ms at %{INT}\.%{INT}\.%{INT}\.%{INT}`,
+		},
+		{
+			"nothing to discover",
+			`%{foo} a.b* x`,
+			grok.DiscoverOptions{},
+			`%\{foo\} a\.b\* x`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			g := grok.New()
+
+			expression, err := g.Discover(tt.Sample, tt.Options)
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, expression)
+
+			require.NoError(t, g.Compile("^"+expression+"$", true))
+			require.True(t, g.MatchString(tt.Sample))
+		})
+	}
+}
+
+func TestDiscoverUnknownPattern(t *testing.T) {
+	_, err := grok.New().Discover("abc", grok.DiscoverOptions{Priority: []string{"MISSING"}})
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+}
@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/elastic/go-grok/ast"
+)
+
+// Expansion describes an expression with references replaced by definitions of referenced patterns.
+type Expansion struct {
+	// Regex is the expanded regular expression in RE2 syntax.
+	Regex string
+	// References are references of the expression, each with references of its definition.
+	References []*ExpandedReference
+	// Fields are fields captured by the expression in order of appearance.
+	Fields []Field
+	// Instructions is the number of instructions of compiled regular expression program,
+	// a rough measure of its size and matching cost.
+	Instructions int
+	// Captures is the number of capture groups, Fields lists the named ones.
+	Captures int
+	// RequiredLiterals are substrings present in every matching text, the longest first,
+	// text lacking any of them is rejected without running the regular expression.
+	RequiredLiterals []string
+}
+
+// ExpandedReference is a reference together with definition of the referenced pattern.
+type ExpandedReference struct {
+	// Raw is the reference as written, e.g "%{INT:process.pid:int}".
+	Raw string
+	// Pattern is the name of referenced pattern, e.g "INT".
+	Pattern string
+	// Field is the name of captured field, empty when not set.
+	Field string
+	// Type is the type hint, empty when not set.
+	Type string
+	// Definition is the definition of referenced pattern.
+	Definition string
+	// References are references of the definition.
+	References []*ExpandedReference
+}
+
+// Field is a captured field.
+type Field struct {
+	Name string
+	// Type is the type hint of the field, empty when not set.
+	Type string
+}
+
+// Expand expands expression without compiling it into grok instance.
+// References without a field name are captured under name of the pattern
+// unless namedCapturesOnly is set, the same way Compile does.
+func (grok *Grok) Expand(expression string, namedCapturesOnly bool) (*Expansion, error) {
+	expanded, hints, err := grok.expand(expression, namedCapturesOnly, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := syntax.Parse(expanded, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	program, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+
+	literals, err := requiredLiterals(expanded)
+	if err != nil {
+		return nil, err
+	}
+
+	// expand succeeded so all references are known and not cyclic
+	references, err := grok.expandedReferences(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expansion{
+		Regex:            expanded,
+		References:       references,
+		Fields:           fields(re, hints),
+		Instructions:     len(program.Inst),
+		Captures:         re.NumSubexp(),
+		RequiredLiterals: literals,
+	}, nil
+}
+
+// Fields returns fields captured by compiled expression in order of appearance.
+func (grok *Grok) Fields() []Field {
+	if grok == nil || grok.re == nil {
+		return nil
+	}
+
+	return fields(grok.re, grok.typeHints)
+}
+
+func fields(re *regexp.Regexp, hints map[string]string) []Field {
+	var fields []Field
+	seen := make(map[string]bool)
+
+	for _, name := range re.SubexpNames() {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		fields = append(fields, Field{Name: fieldName(name), Type: hints[name]})
+	}
+
+	return fields
+}
+
+func (grok *Grok) expandedReferences(expression string) ([]*ExpandedReference, error) {
+	parsed, err := ast.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	var references []*ExpandedReference
+	for _, reference := range ast.References(parsed) {
+		definition, _ := grok.lookupPattern(reference.Syntax)
+
+		children, err := grok.expandedReferences(definition)
+		if err != nil {
+			return nil, err
+		}
+
+		references = append(references, &ExpandedReference{
+			Raw:        reference.Raw,
+			Pattern:    reference.Syntax,
+			Field:      reference.ID,
+			Type:       reference.Type,
+			Definition: definition,
+			References: children,
+		})
+	}
+
+	return references, nil
+}
@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestExpand(t *testing.T) {
+	g, err := grok.NewWithPatterns(map[string]string{
+		"DIGITS":  `\d+`,
+		"PORT":    `%{DIGITS}`,
+		"ADDRESS": `%{WORD:host}:%{PORT:port:int}`,
+	})
+	require.NoError(t, err)
+
+	expansion, err := g.Expand(`%{ADDRESS:address} %{WORD:user.name}`, true)
+	require.NoError(t, err)
+
+	require.Equal(t, `(?P<address>(?P<host>\b\w+\b):(?P<port>(?:\d+))) (?P<user___name>\b\w+\b)`, expansion.Regex)
+	require.Equal(t, []grok.Field{
+		{Name: "address"},
+		{Name: "host"},
+		{Name: "port", Type: "int"},
+		{Name: "user.name"},
+	}, expansion.Fields)
+	// only named fields are captured
+	require.Equal(t, 4, expansion.Captures)
+	require.Greater(t, expansion.Instructions, 0)
+
+	require.Equal(t, []*grok.ExpandedReference{
+		{
+			Raw:        "%{ADDRESS:address}",
+			Pattern:    "ADDRESS",
+			Field:      "address",
+			Definition: `%{WORD:host}:%{PORT:port:int}`,
+			References: []*grok.ExpandedReference{
+				{Raw: "%{WORD:host}", Pattern: "WORD", Field: "host", Definition: `\b\w+\b`},
+				{
+					Raw:        "%{PORT:port:int}",
+					Pattern:    "PORT",
+					Field:      "port",
+					Type:       "int",
+					Definition: `%{DIGITS}`,
+					References: []*grok.ExpandedReference{
+						{Raw: "%{DIGITS}", Pattern: "DIGITS", Definition: `\d+`},
+					},
+				},
+			},
+		},
+		{Raw: "%{WORD:user.name}", Pattern: "WORD", Field: "user.name", Definition: `\b\w+\b`},
+	}, expansion.References)
+
+	// unnamed references are captured under pattern names
+	expansion, err = g.Expand(`%{PORT}`, false)
+	require.NoError(t, err)
+	require.Equal(t, `(?P<PORT>(?P<DIGITS>\d+))`, expansion.Regex)
+	require.Equal(t, []grok.Field{{Name: "PORT"}, {Name: "DIGITS"}}, expansion.Fields)
+
+	_, err = g.Expand(`%{MISSING}`, true)
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+}
+
+func TestExpandNamedCapturesOnly(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	const line = `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`
+
+	named, err := g.Expand("%{HTTPD_COMBINEDLOG}", true)
+	require.NoError(t, err)
+	// unnamed groups of bundled definitions are not captured
+	require.Equal(t, len(named.Fields), named.Captures)
+
+	all, err := g.Expand("%{HTTPD_COMBINEDLOG}", false)
+	require.NoError(t, err)
+	require.Greater(t, all.Captures, 100)
+
+	// results are not affected
+	require.NoError(t, g.Compile("%{HTTPD_COMBINEDLOG}", true))
+	values, err := g.ParseString(line)
+	require.NoError(t, err)
+	require.Regexp(t, all.Regex, line)
+	require.Equal(t, map[string]string{
+		"source.address":            "127.0.0.1",
+		"user.name":                 "frank",
+		"timestamp":                 "10/Oct/2000:13:55:36 -0700",
+		"http.request.method":       "GET",
+		"url.original":              "/apache_pb.gif",
+		"http.version":              "1.0",
+		"http.response.status_code": "200",
+		"http.response.body.size":   "2326",
+		"http.request.referrer":     "http://www.example.com/start.html",
+		"user_agent.original":       "Mozilla/4.08",
+	}, values)
+}
+
+func TestFields(t *testing.T) {
+	g := grok.New()
+	require.Nil(t, g.Fields())
+
+	require.NoError(t, g.Compile(`%{NUMBER:size:long} %{WORD:name} %{WORD:name}`, true, grok.WithRenames(map[string]string{"size": "file.size"})))
+	require.Equal(t, []grok.Field{{Name: "file.size", Type: "long"}, {Name: "name"}}, g.Fields())
+}
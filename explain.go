@@ -0,0 +1,333 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-grok/ast"
+)
+
+// quantifier following an element of expression, e.g `?`, `+?` or `{1,3}`
+var quantifierPattern = regexp.MustCompile(`^(?:[*+?]|\{\d+(?:,\d*)?\})\??`)
+
+// Explanation describes how far text matches compiled expression.
+type Explanation struct {
+	// Matched is true when the whole expression matches text.
+	Matched bool
+	// Expression is the longest part of expression matching text, in the form of expanded regular expression.
+	Expression string
+	// Failed is the source text of the element which failed to match, e.g `(?:[0-9]+)` or `\] `.
+	// Empty when the whole expression matches.
+	Failed string
+	// Reference is the innermost reference enclosing the failed element, e.g "%{INT:process.pid}".
+	// Empty when the failed element is part of the compiled expression itself.
+	Reference string
+	// Path lists names of patterns enclosing the failed element, outermost first, e.g [HAPROXYHTTP HAPROXYHTTPBASE].
+	Path []string
+	// Offset is the byte offset within text where the failed element was expected to match.
+	Offset int
+	// Captures contains fields captured by the longest matching part of expression.
+	// Fields of references enclosing the failed element contain text matched so far.
+	Captures map[string]string
+}
+
+func (e *Explanation) String() string {
+	if e.Matched {
+		return "matched"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "failed to match %q at offset %d", e.Failed, e.Offset)
+	if e.Reference != "" {
+		fmt.Fprintf(&sb, " of %s", e.Reference)
+	}
+	if len(e.Path) > 0 {
+		fmt.Fprintf(&sb, " in %s", strings.Join(e.Path, " > "))
+	}
+
+	names := make([]string, 0, len(e.Captures))
+	for name := range e.Captures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "\n  %s: %q", name, e.Captures[name])
+	}
+
+	return sb.String()
+}
+
+// Explain finds the longest part of compiled expression matching text and reports
+// the element which failed to match next together with fields captured so far.
+// References are descended into, so failure is reported at the innermost element,
+// groups and references which are quantified or contain alternation are treated as a single element.
+func (grok *Grok) Explain(text string) (*Explanation, error) {
+	// precompiled parsers have no source expression
+	if grok == nil || grok.re == nil || grok.precompiled {
+		return nil, ErrNotCompiled
+	}
+
+	if grok.re.MatchString(text) {
+		captures, err := grok.captureString(text)
+		if err != nil {
+			return nil, err
+		}
+		return &Explanation{Matched: true, Expression: grok.re.String(), Offset: len(text), Captures: captures}, nil
+	}
+
+	expression, err := ast.Parse(grok.pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*segment
+	if hasAlternation(expression.Children) {
+		leaf, err := grok.leafSegment(grok.pattern, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		segments = []*segment{leaf}
+	} else if segments, err = grok.segments(expression.Children, "", nil); err != nil {
+		return nil, err
+	}
+
+	var leaves []*segment
+	collectLeaves(segments, &leaves)
+
+	// matching is monotonic, when a prefix of n elements matches every shorter prefix matches as well
+	var searchErr error
+	matching := sort.Search(len(leaves)+1, func(n int) bool {
+		if n == 0 {
+			return false
+		}
+		re, err := grok.compilePrefix(segments, n)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return !re.MatchString(text)
+	}) - 1
+	if searchErr != nil {
+		return nil, searchErr
+	}
+
+	re, err := grok.compilePrefix(segments, matching)
+	if err != nil {
+		return nil, err
+	}
+
+	captures, err := captureTypeFn(re, text, func(v, _ string) (string, error) {
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		Expression: re.String(),
+		Captures:   captures,
+	}
+	if loc := re.FindStringIndex(text); loc != nil {
+		explanation.Offset = loc[1]
+	}
+	if matching < len(leaves) {
+		explanation.Failed = leaves[matching].source
+		explanation.Reference = leaves[matching].reference
+		explanation.Path = leaves[matching].path
+	}
+
+	return explanation, nil
+}
+
+// segment is an element of expression used to build its prefixes,
+// either a leaf rendered as a whole or a group opening containing further segments.
+type segment struct {
+	leaf bool
+	// regex is the expanded leaf or group opening
+	regex     string
+	source    string
+	reference string
+	path      []string
+	children  []*segment
+}
+
+func (grok *Grok) segments(nodes []ast.Node, reference string, path []string) ([]*segment, error) {
+	var segments []*segment
+
+	// bytes of the current literal consumed as quantifier of the preceding element
+	var consumed int
+	for i, node := range nodes {
+		source := node.String()[consumed:]
+		consumed = 0
+
+		// quantifier applies to the preceding element which then can't be split
+		var quantifier string
+		if i+1 < len(nodes) {
+			if next, ok := nodes[i+1].(*ast.Literal); ok {
+				quantifier = quantifierPattern.FindString(next.Value)
+				consumed = len(quantifier)
+			}
+		}
+
+		if source == "" {
+			continue
+		}
+
+		if quantifier == "" {
+			container, err := grok.containerSegment(node, reference, path)
+			if err != nil {
+				return nil, err
+			}
+			if container != nil {
+				segments = append(segments, container)
+				continue
+			}
+		}
+
+		leaf, err := grok.leafSegment(source+quantifier, reference, path)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, leaf)
+	}
+
+	return segments, nil
+}
+
+// containerSegment returns segment for a group or reference which can be descended into, nil otherwise.
+func (grok *Grok) containerSegment(node ast.Node, reference string, path []string) (*segment, error) {
+	switch n := node.(type) {
+	case *ast.Group:
+		if hasAlternation(n.Children) {
+			return nil, nil
+		}
+		children, err := grok.segments(n.Children, reference, path)
+		if err != nil {
+			return nil, err
+		}
+		open := n.Open
+		if n.Kind == ast.NamedCapturing {
+			open = "(?:"
+			if renamed, kept := renamedGroup(n.Name, grok.cfg.renames); kept {
+				open = "(?P<" + renamed + ">"
+			}
+		}
+		return &segment{regex: open, children: children}, nil
+
+	case *ast.Reference:
+		for _, name := range path {
+			if name == n.Syntax {
+				return nil, fmt.Errorf("pattern definition %q references itself: %w", n.Syntax, ErrParseFailure)
+			}
+		}
+
+		knownPattern, found := grok.lookupPattern(n.Syntax)
+		if !found {
+			return nil, fmt.Errorf("pattern definition %q unknown: %w", n.Syntax, ErrParseFailure)
+		}
+		definition, err := ast.Parse(knownPattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern definition %q: %v: %w", n.Syntax, err, ErrParseFailure)
+		}
+		if hasAlternation(definition.Children) {
+			return nil, nil
+		}
+
+		children, err := grok.segments(definition.Children, n.Raw, append(append([]string(nil), path...), n.Syntax))
+		if err != nil {
+			return nil, err
+		}
+
+		open := "(?:"
+		if !grok.namedCapturesOnly || n.ID != "" {
+			targetId := n.Syntax
+			if n.ID != "" {
+				targetId = groupName(n.ID)
+			}
+			if renamed, kept := renamedGroup(targetId, grok.cfg.renames); kept {
+				open = "(?P<" + renamed + ">"
+			}
+		}
+		return &segment{regex: open, children: children}, nil
+	}
+
+	return nil, nil
+}
+
+func (grok *Grok) leafSegment(source, reference string, path []string) (*segment, error) {
+	expanded, _, err := grok.expand(source, grok.namedCapturesOnly, grok.cfg.renames)
+	if err != nil {
+		return nil, err
+	}
+	return &segment{leaf: true, regex: expanded, source: source, reference: reference, path: path}, nil
+}
+
+// compilePrefix compiles expression made of the first n leaves, enclosing groups are closed.
+func (grok *Grok) compilePrefix(segments []*segment, n int) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	writePrefix(&sb, segments, &n)
+
+	return regexp.Compile(sb.String())
+}
+
+// writePrefix writes leaves while n is positive, reports whether all segments were written.
+func writePrefix(sb *strings.Builder, segments []*segment, n *int) bool {
+	for _, s := range segments {
+		if *n == 0 {
+			return false
+		}
+
+		if s.leaf {
+			sb.WriteString(s.regex)
+			*n--
+			continue
+		}
+
+		sb.WriteString(s.regex)
+		complete := writePrefix(sb, s.children, n)
+		sb.WriteString(")")
+		if !complete {
+			return false
+		}
+	}
+
+	return true
+}
+
+func collectLeaves(segments []*segment, leaves *[]*segment) {
+	for _, s := range segments {
+		if s.leaf {
+			*leaves = append(*leaves, s)
+			continue
+		}
+		collectLeaves(s.children, leaves)
+	}
+}
+
+func hasAlternation(nodes []ast.Node) bool {
+	for _, node := range nodes {
+		if _, ok := node.(*ast.Alternation); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestExplain(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Pattern  string
+		Text     string
+		Expected grok.Explanation
+	}{
+		{
+			"failed literal",
+			`%{IP:client} %{WORD:method} \[%{INT:code:int}\]`,
+			"1.2.3.4 GET [12x]",
+			grok.Explanation{
+				Failed:   `\]`,
+				Offset:   15,
+				Captures: map[string]string{"client": "1.2.3.4", "method": "GET", "code": "12"},
+			},
+		},
+		{
+			"failed reference",
+			`^%{IP:client} %{INT:size}$`,
+			"1.2.3.4 abc",
+			grok.Explanation{
+				Failed:    "[0-9]+",
+				Reference: "%{INT:size}",
+				Path:      []string{"INT"},
+				Offset:    8,
+				Captures:  map[string]string{"client": "1.2.3.4"},
+			},
+		},
+		{
+			"failed inside referenced pattern",
+			`%{PAIR:pair}`,
+			"key=",
+			grok.Explanation{
+				Failed:    `\b\w+\b`,
+				Reference: "%{WORD:value}",
+				Path:      []string{"PAIR", "WORD"},
+				Offset:    4,
+				Captures:  map[string]string{"key": "key", "pair": "key="},
+			},
+		},
+		{
+			"optional reference is a single element",
+			`^%{WORD:a}(?: %{INT:b})? %{INT:c}$`,
+			"abc 1 x",
+			grok.Explanation{
+				Failed:   "$",
+				Offset:   5,
+				Captures: map[string]string{"a": "abc", "c": "1"},
+			},
+		},
+		{
+			"alternation is a single element",
+			`^(?:%{INT:a}|%{WORD:b}) x`,
+			"- x",
+			grok.Explanation{
+				Failed:   "(?:%{INT:a}|%{WORD:b})",
+				Offset:   0,
+				Captures: map[string]string{},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			g, err := grok.NewWithPatterns(map[string]string{"PAIR": `%{WORD:key}=%{WORD:value}`})
+			require.NoError(t, err)
+			require.NoError(t, g.Compile(tt.Pattern, true))
+
+			explanation, err := g.Explain(tt.Text)
+			require.NoError(t, err)
+			require.False(t, explanation.Matched)
+			require.Equal(t, tt.Expected.Failed, explanation.Failed)
+			require.Equal(t, tt.Expected.Reference, explanation.Reference)
+			require.Equal(t, tt.Expected.Path, explanation.Path)
+			require.Equal(t, tt.Expected.Offset, explanation.Offset)
+			require.Equal(t, tt.Expected.Captures, explanation.Captures)
+		})
+	}
+}
+
+func TestExplain_BundledPattern(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+	require.NoError(t, g.Compile("%{HAPROXYHTTP}", true))
+
+	line := `Sep 14 02:01:37 lb-001 haproxy[14134]: 1.2.3.4:5678 [14/Sep/2014:00:00:00.000] frontend backend/server 1/2/x/4/5 200 1234 - - ---- 1/1/0/1/0 0/0 "GET / HTTP/1.1"`
+	explanation, err := g.Explain(line)
+	require.NoError(t, err)
+	require.Equal(t, []string{"HAPROXYHTTP", "HAPROXYHTTPBASE"}, explanation.Path)
+	require.Equal(t, "(?:-1|%{INT:haproxy.connection_wait_time_ms:int})", explanation.Failed)
+	require.Equal(t, "%{HAPROXYHTTPBASE}", explanation.Reference)
+	require.Equal(t, 107, explanation.Offset)
+	require.Equal(t, "x/4/5", line[explanation.Offset:explanation.Offset+5])
+	require.Equal(t, "frontend", explanation.Captures["haproxy.frontend_name"])
+	require.NotContains(t, explanation.Captures, "haproxy.connection_wait_time_ms")
+}
+
+func TestExplain_Matched(t *testing.T) {
+	g := grok.New()
+	require.NoError(t, g.Compile("%{WORD:a}", true, grok.WithRenames(map[string]string{"a": "b"})))
+
+	explanation, err := g.Explain("abc")
+	require.NoError(t, err)
+	require.True(t, explanation.Matched)
+	require.Equal(t, map[string]string{"b": "abc"}, explanation.Captures)
+
+	_, err = grok.New().Explain("abc")
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+
+	// empty expression is compiled
+	require.NoError(t, g.Compile("", true))
+	explanation, err = g.Explain("abc")
+	require.NoError(t, err)
+	require.True(t, explanation.Matched)
+	_, err = g.InferTypes([]string{"abc"})
+	require.NoError(t, err)
+}
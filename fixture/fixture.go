@@ -0,0 +1,351 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package fixture runs declarative pattern tests described in YAML or JSON files.
+//
+// A fixture file lists test cases, each with an expression, input lines and fields expected to be captured:
+//
+//	patterns:
+//	  APP_ID: '[a-z]+-%{INT}'
+//	tests:
+//	  - name: application id
+//	    pattern: 'id=%{APP_ID:app.id}'
+//	    lines: ['id=abc-1']
+//	    fields:
+//	      app.id: abc-1
+//	  - name: not an id
+//	    pattern: 'id=%{APP_ID:app.id}'
+//	    lines: ['id=1']
+//	    no_match: true
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/patterns"
+)
+
+// File is a set of test cases sharing pattern definitions.
+type File struct {
+	// Legacy selects bundled patterns with legacy field names instead of ECS.
+	Legacy bool `yaml:"legacy" json:"legacy"`
+	// Patterns are additional definitions available to all tests of the file.
+	Patterns map[string]string `yaml:"patterns" json:"patterns"`
+	Tests    []Test            `yaml:"tests" json:"tests"`
+}
+
+// Test is a single test case.
+type Test struct {
+	Name string `yaml:"name" json:"name"`
+	// Pattern is the grok expression under test.
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Lines are inputs, each of them is expected to produce Fields.
+	Lines []string `yaml:"lines" json:"lines"`
+	// Fields are expected captures, compared with typed values when Typed is set.
+	Fields map[string]Value `yaml:"fields" json:"fields"`
+	// Typed compares values converted according to type hints.
+	Typed bool `yaml:"typed" json:"typed"`
+	// Partial ignores captured fields not listed in Fields.
+	Partial bool `yaml:"partial" json:"partial"`
+	// NoMatch expects lines not to match the expression.
+	NoMatch bool `yaml:"no_match" json:"no_match"`
+}
+
+// Value is an expected value of a field.
+// It keeps textual form of the value as written in fixture for comparison with values not converted to types,
+// e.g 1.0 is expected to be captured as "1.0" and not as "1".
+type Value struct {
+	Text  string
+	Typed interface{}
+}
+
+func (v *Value) UnmarshalYAML(node *yaml.Node) error {
+	v.Text = node.Value
+	return node.Decode(&v.Typed)
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &v.Typed); err != nil {
+		return err
+	}
+
+	v.Text = string(data)
+	if s, ok := v.Typed.(string); ok {
+		v.Text = s
+	}
+	return nil
+}
+
+// Options configures Run.
+type Options struct {
+	// Patterns are additional definitions, definitions of fixture files take precedence.
+	Patterns []map[string]string
+	// Legacy selects bundled patterns with legacy field names for all fixture files,
+	// otherwise they are selected by fixture files.
+	Legacy bool
+}
+
+// Result is an outcome of a test case for a single line.
+type Result struct {
+	Test string
+	Line string
+	// Err is set when the test could not be executed, e.g expression does not compile.
+	Err   error
+	Diffs []Diff
+}
+
+// Passed reports whether the line produced expected result.
+func (r Result) Passed() bool {
+	return r.Err == nil && len(r.Diffs) == 0
+}
+
+func (r Result) String() string {
+	if r.Passed() {
+		return fmt.Sprintf("PASS %s", r.Test)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "FAIL %s\n  line: %q", r.Test, r.Line)
+	if r.Err != nil {
+		fmt.Fprintf(&sb, "\n  error: %v", r.Err)
+	}
+	for _, d := range r.Diffs {
+		fmt.Fprintf(&sb, "\n  %s", d)
+	}
+	return sb.String()
+}
+
+// DiffKind describes how an actual field differs from expectation.
+type DiffKind string
+
+const (
+	// Missing field is expected but was not captured.
+	Missing DiffKind = "missing"
+	// Unexpected field was captured but is not expected.
+	Unexpected DiffKind = "unexpected"
+	// Changed field was captured with a different value.
+	Changed DiffKind = "changed"
+	// Match reports line which did (not) match contrary to expectation, Field is empty.
+	Match DiffKind = "match"
+)
+
+// Diff is a single difference between expected and actual result.
+type Diff struct {
+	Kind     DiffKind
+	Field    string
+	Expected interface{}
+	Actual   interface{}
+}
+
+func (d Diff) String() string {
+	switch d.Kind {
+	case Missing:
+		return fmt.Sprintf("- %s: %s", d.Field, describe(d.Expected))
+	case Unexpected:
+		return fmt.Sprintf("+ %s: %s", d.Field, describe(d.Actual))
+	case Match:
+		if d.Expected == true {
+			return "expected line to match"
+		}
+		return "expected line not to match"
+	default:
+		return fmt.Sprintf("~ %s: expected %s, got %s", d.Field, describe(d.Expected), describe(d.Actual))
+	}
+}
+
+func describe(v interface{}) string {
+	return fmt.Sprintf("%#v (%T)", v, v)
+}
+
+// Load reads a fixture file, format is selected by extension: .yaml, .yml or .json.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f *File
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		f, err = ReadYAML(bytes.NewReader(data))
+	case ".json":
+		f, err = ReadJSON(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%s: unsupported fixture format, expected .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ReadYAML reads fixture in YAML format.
+func ReadYAML(r io.Reader) (*File, error) {
+	var f File
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ReadJSON reads fixture in JSON format.
+func ReadJSON(r io.Reader) (*File, error) {
+	var f File
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Run executes all tests of the fixture file and returns a result per test and line.
+func Run(f *File, opts Options) []Result {
+	collection := patterns.ECSv1
+	if f.Legacy || opts.Legacy {
+		collection = patterns.Legacy
+	}
+
+	definitions := append(append([]map[string]string(nil), opts.Patterns...), f.Patterns)
+
+	var results []Result
+	for _, test := range f.Tests {
+		g, err := grok.NewCompleteWithCollection(collection, definitions...)
+		if err == nil {
+			err = g.Compile(test.Pattern, true)
+		}
+		if err != nil {
+			results = append(results, Result{Test: test.Name, Err: err})
+			continue
+		}
+
+		for _, line := range test.Lines {
+			results = append(results, runLine(g, test, line))
+		}
+	}
+
+	return results
+}
+
+func runLine(g *grok.Grok, test Test, line string) Result {
+	result := Result{Test: test.Name, Line: line}
+
+	matched := g.MatchString(line)
+	if matched == test.NoMatch {
+		result.Diffs = []Diff{{Kind: Match, Expected: !test.NoMatch, Actual: matched}}
+		return result
+	}
+	if !matched {
+		return result
+	}
+
+	actual := make(map[string]interface{})
+	if test.Typed {
+		values, err := g.ParseTypedString(line)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		actual = values
+	} else {
+		values, err := g.ParseString(line)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		for k, v := range values {
+			actual[k] = v
+		}
+	}
+
+	result.Diffs = compare(test.Fields, actual, test.Partial, test.Typed)
+	return result
+}
+
+func compare(expected map[string]Value, actual map[string]interface{}, partial, typed bool) []Diff {
+	var diffs []Diff
+
+	for field, value := range expected {
+		var want interface{} = value.Text
+		if typed {
+			want = value.Typed
+		}
+
+		got, found := actual[field]
+		switch {
+		case !found:
+			diffs = append(diffs, Diff{Kind: Missing, Field: field, Expected: want})
+		case !equal(want, got, typed):
+			diffs = append(diffs, Diff{Kind: Changed, Field: field, Expected: want, Actual: got})
+		}
+	}
+
+	if !partial {
+		for field, got := range actual {
+			if _, found := expected[field]; !found {
+				diffs = append(diffs, Diff{Kind: Unexpected, Field: field, Actual: got})
+			}
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+	return diffs
+}
+
+// equal compares expected value decoded from fixture with actual one.
+// Numbers are compared by value as YAML and JSON decode them differently from ParseTyped.
+func equal(want, got interface{}, typed bool) bool {
+	if !typed {
+		return want == got
+	}
+
+	wantNumber, wantIsNumber := number(want)
+	gotNumber, gotIsNumber := number(got)
+	if wantIsNumber || gotIsNumber {
+		return wantIsNumber && gotIsNumber && wantNumber == gotNumber
+	}
+
+	return want == got
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
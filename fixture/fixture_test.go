@@ -0,0 +1,111 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package fixture_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok/fixture"
+)
+
+func TestRun_Passing(t *testing.T) {
+	for _, path := range []string{"testdata/httpd.yaml", "testdata/custom.json"} {
+		t.Run(path, func(t *testing.T) {
+			f, err := fixture.Load(path)
+			require.NoError(t, err)
+
+			results := fixture.Run(f, fixture.Options{})
+			require.NotEmpty(t, results)
+			for _, result := range results {
+				require.True(t, result.Passed(), result.String())
+			}
+		})
+	}
+}
+
+func TestRun_Failing(t *testing.T) {
+	f, err := fixture.ReadYAML(strings.NewReader(`
+legacy: true
+tests:
+  - name: legacy common log
+    pattern: '%{HTTPD_COMMONLOG}'
+    lines: ['127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326']
+    fields:
+      clientip: 127.0.0.1
+      auth: bob
+      response: 200
+      bytes: 2326
+      httpversion: '1.0'
+      timestamp: 10/Oct/2000:13:55:36 -0700
+      request: /apache_pb.gif
+      referrer: '-'
+  - name: unexpected match
+    pattern: '%{INT}'
+    lines: ['12']
+    no_match: true
+  - name: invalid
+    pattern: '%{MISSING}'
+`))
+	require.NoError(t, err)
+
+	results := fixture.Run(f, fixture.Options{})
+	require.Len(t, results, 3)
+
+	require.Equal(t, []fixture.Diff{
+		{Kind: fixture.Changed, Field: "auth", Expected: "bob", Actual: "frank"},
+		{Kind: fixture.Missing, Field: "referrer", Expected: "-"},
+		{Kind: fixture.Unexpected, Field: "verb", Actual: "GET"},
+	}, results[0].Diffs)
+	require.Equal(t, `FAIL legacy common log
+  line: "127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] \"GET /apache_pb.gif HTTP/1.0\" 200 2326"
+  ~ auth: expected "bob" (string), got "frank" (string)
+  - referrer: "-" (string)
+  + verb: "GET" (string)`, results[0].String())
+
+	require.Equal(t, []fixture.Diff{{Kind: fixture.Match, Expected: false, Actual: true}}, results[1].Diffs)
+	require.Contains(t, results[1].String(), "expected line not to match")
+
+	require.Error(t, results[2].Err)
+	require.False(t, results[2].Passed())
+}
+
+func TestRun_UntypedComparesText(t *testing.T) {
+	f, err := fixture.ReadJSON(strings.NewReader(`{"tests": [{"name": "n", "pattern": "%{NUMBER:n:float}", "lines": ["1.50"], "fields": {"n": 1.5}}]}`))
+	require.NoError(t, err)
+
+	results := fixture.Run(f, fixture.Options{})
+	require.Equal(t, []fixture.Diff{{Kind: fixture.Changed, Field: "n", Expected: "1.5", Actual: "1.50"}}, results[0].Diffs)
+
+	f.Tests[0].Typed = true
+	results = fixture.Run(f, fixture.Options{})
+	require.True(t, results[0].Passed(), results[0].String())
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := fixture.Load("testdata/missing.yaml")
+	require.Error(t, err)
+
+	_, err = fixture.ReadYAML(strings.NewReader("tests:\n  - nmae: typo\n"))
+	require.Error(t, err)
+
+	_, err = fixture.Load("fixture.go")
+	require.ErrorContains(t, err, "unsupported fixture format")
+}
@@ -0,0 +1,308 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package generate produces random lines matching a grok expression, e.g. to fuzz pipelines or test patterns.
+//
+// The expanded regular expression is walked using regexp/syntax and a random string matching every
+// element is emitted. References to patterns with a value provider, e.g. IPV4 or HTTPDATE,
+// are filled with realistic values instead. Every generated line is verified to match the whole expression.
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/ast"
+)
+
+// markerPrefix names capture groups of references filled by providers
+const markerPrefix = "__provided_"
+
+// ErrNoMatch is returned when no generated line matched the expression within MaxAttempts.
+var ErrNoMatch = errors.New("failed to generate matching line")
+
+// Options configures the generator.
+type Options struct {
+	// Rand is the source of randomness, lines are reproducible with the same seed.
+	// Seeded by current time when nil.
+	Rand *rand.Rand
+	// Providers generate values of referenced patterns by pattern name, they take precedence over DefaultProviders.
+	// Values not matching the pattern are ignored and the pattern is generated from its regular expression.
+	Providers map[string]Provider
+	// MaxRepeat limits unbounded repetitions like `*` and `+`, 8 when not set.
+	MaxRepeat int
+	// MaxAttempts limits attempts to generate a matching line, 100 when not set.
+	MaxAttempts int
+}
+
+// Generator generates lines matching a grok expression, it is not safe for concurrent use.
+type Generator struct {
+	rand        *rand.Rand
+	tree        *syntax.Regexp
+	verify      *regexp.Regexp
+	maxRepeat   int
+	maxAttempts int
+	// provided are providers of reference capture groups keyed by capture group name
+	provided map[string]*provided
+	// mixProvided uses providers at random instead of always
+	mixProvided bool
+}
+
+type provided struct {
+	provide Provider
+	verify  *regexp.Regexp
+}
+
+// New creates a generator of lines matching expression using patterns known to g.
+func New(g *grok.Grok, expression string, opts Options) (*Generator, error) {
+	expansion, err := g.Expand(expression, true)
+	if err != nil {
+		return nil, err
+	}
+
+	verify, err := regexp.Compile("^(?:" + expansion.Regex + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &Generator{
+		rand:        opts.Rand,
+		verify:      verify,
+		maxRepeat:   opts.MaxRepeat,
+		maxAttempts: opts.MaxAttempts,
+		provided:    make(map[string]*provided),
+	}
+	if gen.rand == nil {
+		gen.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if gen.maxRepeat <= 0 {
+		gen.maxRepeat = 8
+	}
+	if gen.maxAttempts <= 0 {
+		gen.maxAttempts = 100
+	}
+
+	b := builder{providers: opts.Providers, provided: gen.provided}
+	if err := b.write(expression, expansion.References); err != nil {
+		return nil, err
+	}
+
+	if gen.tree, err = syntax.Parse(b.sb.String(), syntax.Perl); err != nil {
+		return nil, err
+	}
+
+	return gen, nil
+}
+
+// Generate returns a random line matching the whole expression.
+func (gen *Generator) Generate() (string, error) {
+	var sb strings.Builder
+	for i := 0; i < gen.maxAttempts; i++ {
+		// provided values may conflict with surrounding elements, e.g. word boundaries,
+		// second half of attempts uses them only at random
+		gen.mixProvided = i >= gen.maxAttempts/2
+
+		sb.Reset()
+		gen.walk(&sb, gen.tree)
+
+		if line := sb.String(); gen.verify.MatchString(line) {
+			return line, nil
+		}
+	}
+
+	return "", fmt.Errorf("%d attempts: %w", gen.maxAttempts, ErrNoMatch)
+}
+
+// builder expands expression the same way grok does, references are wrapped in non capturing groups
+// except for references with a provider which are captured under a marker name.
+// Providers are not used when provided is nil.
+type builder struct {
+	sb        strings.Builder
+	providers map[string]Provider
+	provided  map[string]*provided
+}
+
+func (b *builder) write(source string, references []*grok.ExpandedReference) error {
+	parsed, err := ast.Parse(source)
+	if err != nil {
+		return err
+	}
+
+	next := 0
+	return b.writeNodes(parsed.Children, references, &next)
+}
+
+// writeNodes writes nodes, references are matched with expanded ones by their order.
+func (b *builder) writeNodes(nodes []ast.Node, references []*grok.ExpandedReference, next *int) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ast.Group:
+			b.sb.WriteString(n.Open)
+			if err := b.writeNodes(n.Children, references, next); err != nil {
+				return err
+			}
+			b.sb.WriteString(")")
+
+		case *ast.Reference:
+			reference := references[*next]
+			*next++
+
+			var provider Provider
+			if b.provided != nil {
+				provider = b.providers[reference.Pattern]
+				if provider == nil {
+					provider = DefaultProviders[reference.Pattern]
+				}
+			}
+			if provider == nil {
+				b.sb.WriteString("(?:")
+				if err := b.write(reference.Definition, reference.References); err != nil {
+					return err
+				}
+				b.sb.WriteString(")")
+				continue
+			}
+
+			// definition is expanded separately to verify provided values
+			var definition builder
+			if err := definition.write(reference.Definition, reference.References); err != nil {
+				return err
+			}
+			verify, err := regexp.Compile("^(?:" + definition.sb.String() + ")$")
+			if err != nil {
+				return err
+			}
+
+			name := markerPrefix + strconv.Itoa(len(b.provided))
+			b.provided[name] = &provided{provide: provider, verify: verify}
+			b.sb.WriteString("(?P<" + name + ">" + definition.sb.String() + ")")
+
+		default:
+			b.sb.WriteString(node.String())
+		}
+	}
+
+	return nil
+}
+
+func (gen *Generator) walk(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && gen.rand.Intn(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			sb.WriteRune(r)
+		}
+
+	case syntax.OpCharClass:
+		sb.WriteRune(gen.classRune(re.Rune))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(gen.classRune([]rune{' ', '~'}))
+
+	case syntax.OpCapture:
+		if p, found := gen.provided[re.Name]; found && (!gen.mixProvided || gen.rand.Intn(2) == 0) {
+			if value := p.provide(gen.rand); p.verify.MatchString(value) {
+				sb.WriteString(value)
+				return
+			}
+		}
+		gen.walk(sb, re.Sub[0])
+
+	case syntax.OpStar:
+		gen.repeat(sb, re.Sub[0], 0, gen.maxRepeat)
+	case syntax.OpPlus:
+		gen.repeat(sb, re.Sub[0], 1, gen.maxRepeat)
+	case syntax.OpQuest:
+		gen.repeat(sb, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + gen.maxRepeat
+		}
+		gen.repeat(sb, re.Sub[0], re.Min, max)
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			gen.walk(sb, sub)
+		}
+
+	case syntax.OpAlternate:
+		gen.walk(sb, re.Sub[gen.rand.Intn(len(re.Sub))])
+	}
+
+	// empty matches, anchors and word boundaries do not produce any text,
+	// lines violating them are rejected by verification
+}
+
+func (gen *Generator) repeat(sb *strings.Builder, re *syntax.Regexp, min, max int) {
+	n := min
+	if max > min {
+		n += gen.rand.Intn(max - min + 1)
+	}
+	for i := 0; i < n; i++ {
+		gen.walk(sb, re)
+	}
+}
+
+// classRune picks a random rune of character class given as pairs of ranges,
+// printable ASCII characters are preferred as negated classes span all of Unicode.
+func (gen *Generator) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+
+	var total int
+	for i := 0; i+1 < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if total == 0 {
+		return 'x'
+	}
+
+	n := gen.rand.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	return ranges[0]
+}
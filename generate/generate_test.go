@@ -0,0 +1,135 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package generate_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/generate"
+	"github.com/elastic/go-grok/patterns"
+)
+
+func TestGenerate(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	expression := `%{IPV4:source.ip} %{WORD:method} %{URIPATH:path} [a-f]{4} (?:%{INT:status}|-)`
+	require.NoError(t, g.Compile("^"+expression+"$", true))
+
+	gen, err := generate.New(g, expression, generate.Options{Rand: rand.New(rand.NewSource(1))})
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		line, err := gen.Generate()
+		require.NoError(t, err)
+		require.True(t, g.MatchString(line), line)
+	}
+}
+
+func TestGenerate_Reproducible(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	generateLines := func(seed int64) []string {
+		gen, err := generate.New(g, "%{COMBINEDAPACHELOG}", generate.Options{Rand: rand.New(rand.NewSource(seed))})
+		require.NoError(t, err)
+
+		lines := make([]string, 5)
+		for i := range lines {
+			lines[i], err = gen.Generate()
+			require.NoError(t, err)
+		}
+		return lines
+	}
+
+	require.Equal(t, generateLines(42), generateLines(42))
+	require.NotEqual(t, generateLines(42), generateLines(43))
+}
+
+func TestGenerate_Providers(t *testing.T) {
+	g, err := grok.NewWithPatterns(map[string]string{"TEAM": `[a-z]+`})
+	require.NoError(t, err)
+
+	gen, err := generate.New(g, "%{TEAM:team}/%{IPV4}", generate.Options{
+		Providers: map[string]generate.Provider{
+			"TEAM": func(*rand.Rand) string { return "platform" },
+			"IPV4": func(*rand.Rand) string { return "not an ip" },
+		},
+		Rand: rand.New(rand.NewSource(1)),
+	})
+	require.NoError(t, err)
+
+	line, err := gen.Generate()
+	require.NoError(t, err)
+	// invalid provided values are replaced by generated ones
+	require.Regexp(t, `^platform/\d+\.\d+\.\d+\.\d+$`, line)
+
+	gen, err = generate.New(g, `\bx\b\Bx`, generate.Options{MaxAttempts: 3})
+	require.NoError(t, err)
+	_, err = gen.Generate()
+	require.ErrorIs(t, err, generate.ErrNoMatch)
+
+	_, err = generate.New(g, "%{MISSING}", generate.Options{})
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+}
+
+// TestGenerate_BundledPatterns checks every bundled pattern generates lines it matches as a whole.
+func TestGenerate_BundledPatterns(t *testing.T) {
+	for name, collection := range map[string]patterns.Collection{"ecs": patterns.ECSv1, "legacy": patterns.Legacy} {
+		g, err := grok.NewCompleteWithCollection(collection)
+		require.NoError(t, err)
+
+		var patternNames []string
+		for _, definitions := range collection.All() {
+			for patternName := range definitions {
+				patternNames = append(patternNames, patternName)
+			}
+		}
+		sort.Strings(patternNames)
+
+		t.Run(name, func(t *testing.T) {
+			for _, patternName := range patternNames {
+				gen, err := generate.New(g, "%{"+patternName+"}", generate.Options{Rand: rand.New(rand.NewSource(1))})
+				require.NoError(t, err, patternName)
+				require.NoError(t, g.Compile("^%{"+patternName+"}$", true))
+
+				for i := 0; i < 10; i++ {
+					line, err := gen.Generate()
+					if !assertNoError(t, err, patternName) {
+						break
+					}
+					require.True(t, g.MatchString(line), "%s: %q", patternName, line)
+				}
+			}
+		})
+	}
+}
+
+func assertNoError(t *testing.T, err error, name string) bool {
+	t.Helper()
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return false
+	}
+	return true
+}
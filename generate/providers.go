@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package generate
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider returns a realistic value of a pattern.
+type Provider func(r *rand.Rand) string
+
+// DefaultProviders are providers of bundled patterns used unless overridden in Options.
+var DefaultProviders = map[string]Provider{
+	"IPV4":              ipv4,
+	"IPV6":              ipv6,
+	"HOSTNAME":          hostname,
+	"IPORHOST":          oneOf(ipv4, hostname),
+	"USERNAME":          username,
+	"USER":              username,
+	"EMAILADDRESS":      email,
+	"UUID":              uuid,
+	"MAC":               mac,
+	"INT":               integer(-1000, 10000),
+	"POSINT":            integer(1, 65535),
+	"NONNEGINT":         integer(0, 65535),
+	"NUMBER":            oneOf(integer(0, 100000), decimal),
+	"BASE10NUM":         oneOf(integer(0, 100000), decimal),
+	"WORD":              word,
+	"NOTSPACE":          oneOf(word, hostname, path),
+	"DATA":              sentence,
+	"GREEDYDATA":        sentence,
+	"QUOTEDSTRING":      quoted,
+	"LOGLEVEL":          choice("DEBUG", "INFO", "WARN", "ERROR", "debug", "info", "warning", "error"),
+	"URIPATH":           path,
+	"URIPATHPARAM":      oneOf(path, pathWithQuery),
+	"URI":               uri,
+	"HTTPDATE":          timestamp("02/Jan/2006:15:04:05 -0700"),
+	"TIMESTAMP_ISO8601": oneOf(timestamp(time.RFC3339), timestamp("2006-01-02T15:04:05.000Z07:00"), timestamp("2006-01-02 15:04:05,000")),
+	"SYSLOGTIMESTAMP":   timestamp(time.Stamp),
+}
+
+var (
+	words = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliett",
+		"kilo", "lima", "mike", "november", "oscar", "papa", "quebec", "romeo", "sierra", "tango"}
+	names   = []string{"alice", "bob", "carol", "dave", "eve", "frank", "grace", "heidi", "ivan", "judy"}
+	domains = []string{"example.com", "example.org", "example.net", "internal.local"}
+	zones   = []*time.Location{time.UTC, time.FixedZone("", -7*3600), time.FixedZone("", 2*3600), time.FixedZone("", 5*3600+1800)}
+)
+
+func oneOf(providers ...Provider) Provider {
+	return func(r *rand.Rand) string {
+		return providers[r.Intn(len(providers))](r)
+	}
+}
+
+func choice(values ...string) Provider {
+	return func(r *rand.Rand) string {
+		return values[r.Intn(len(values))]
+	}
+}
+
+func integer(min, max int) Provider {
+	return func(r *rand.Rand) string {
+		return strconv.Itoa(min + r.Intn(max-min+1))
+	}
+}
+
+func decimal(r *rand.Rand) string {
+	return strconv.FormatFloat(float64(r.Intn(1000000))/1000, 'f', 3, 64)
+}
+
+func ipv4(r *rand.Rand) string {
+	return fmt.Sprintf("%d.%d.%d.%d", 1+r.Intn(223), r.Intn(256), r.Intn(256), 1+r.Intn(254))
+}
+
+func ipv6(r *rand.Rand) string {
+	return fmt.Sprintf("2001:db8:%x:%x::%x", r.Intn(0x10000), r.Intn(0x10000), 1+r.Intn(0xffff))
+}
+
+func word(r *rand.Rand) string {
+	return words[r.Intn(len(words))]
+}
+
+func username(r *rand.Rand) string {
+	return names[r.Intn(len(names))]
+}
+
+func hostname(r *rand.Rand) string {
+	return word(r) + "-" + strconv.Itoa(1+r.Intn(20)) + "." + domains[r.Intn(len(domains))]
+}
+
+func email(r *rand.Rand) string {
+	return username(r) + "@" + domains[r.Intn(len(domains))]
+}
+
+func uuid(r *rand.Rand) string {
+	b := make([]byte, 16)
+	r.Read(b)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func mac(r *rand.Rand) string {
+	b := make([]byte, 6)
+	r.Read(b)
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", b[0], b[1], b[2], b[3], b[4], b[5])
+}
+
+func sentence(r *rand.Rand) string {
+	parts := make([]string, 1+r.Intn(5))
+	for i := range parts {
+		parts[i] = word(r)
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoted(r *rand.Rand) string {
+	return `"` + sentence(r) + `"`
+}
+
+func path(r *rand.Rand) string {
+	parts := make([]string, 1+r.Intn(3))
+	for i := range parts {
+		parts[i] = word(r)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func pathWithQuery(r *rand.Rand) string {
+	return path(r) + "?" + word(r) + "=" + strconv.Itoa(r.Intn(100))
+}
+
+func uri(r *rand.Rand) string {
+	return "https://" + hostname(r) + pathWithQuery(r)
+}
+
+// timestamp returns provider of times within years 2000 to 2030 formatted with layout.
+func timestamp(layout string) Provider {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	end := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	return func(r *rand.Rand) string {
+		t := time.Unix(start+r.Int63n(end-start), int64(r.Intn(1000))*int64(time.Millisecond))
+		return t.In(zones[r.Intn(len(zones))]).Format(layout)
+	}
+}
@@ -18,11 +18,13 @@
 package grok
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/elastic/go-grok/ast"
 	"github.com/elastic/go-grok/patterns"
 )
 
@@ -32,15 +34,8 @@ var (
 	ErrParseFailure    = fmt.Errorf("parsing failed")
 	ErrTypeNotProvided = fmt.Errorf("type not specified")
 	ErrUnsupportedName = fmt.Errorf("name contains unsupported character ':'")
-
-	// grok can be specified in either of these forms:
-	// %{SYNTAX} - e.g {NUMBER}
-	// %{SYNTAX:ID} - e.g {NUMBER:MY_AGE}
-	// %{SYNTAX:ID:TYPE} - e.g {NUMBER:MY_AGE:INT}
-	// supported types are int, long, double, float and boolean
-	// for go specific implementation int and long results in int
-	// double and float both results in float
-	reusePattern = regexp.MustCompile(`%{(\w+(?::[\w+.]+(?::\w+)?)?)}`)
+	ErrRenameConflict  = fmt.Errorf("rename target collides with existing field")
+	ErrNotCompiled     = fmt.Errorf("expression not compiled")
 )
 
 type Grok struct {
@@ -48,6 +43,20 @@ type Grok struct {
 	re                    *regexp.Regexp
 	typeHints             map[string]string
 	lookupDefaultPatterns bool
+
+	// literals are required by every match of re, text lacking them is rejected before matching
+	literals []string
+	// literalBytes are literals for text in a form of []byte
+	literalBytes [][]byte
+	// matchFirst matches text before extracting submatches, see WithMatchFirst
+	matchFirst bool
+
+	// compiled expression and its settings kept for introspection, e.g Explain
+	// precompiled is set by NewPrecompiled, pattern is not known then
+	precompiled       bool
+	pattern           string
+	namedCapturesOnly bool
+	cfg               compileConfig
 }
 
 func New() *Grok {
@@ -80,26 +89,13 @@ func NewWithPatterns(patterns ...map[string]string) (*Grok, error) {
 
 // NewComplete creates a grok parser with full set of patterns
 func NewComplete(additionalPatterns ...map[string]string) (*Grok, error) {
-	g, err := NewWithPatterns(
-		patterns.AWS,
-		patterns.Bind9,
-		patterns.Bro,
-		patterns.Exim,
-		patterns.HAProxy,
-		patterns.Httpd,
-		patterns.Firewalls,
-		patterns.Java,
-		patterns.Junos,
-		patterns.Maven,
-		patterns.MCollective,
-		patterns.MongoDB,
-		patterns.PostgreSQL,
-		patterns.Rails,
-		patterns.Redis,
-		patterns.Ruby,
-		patterns.Squid,
-		patterns.Syslog,
-	)
+	return NewCompleteWithCollection(patterns.ECSv1, additionalPatterns...)
+}
+
+// NewCompleteWithCollection creates a grok parser with full set of patterns
+// taken from provided collection, e.g patterns.Legacy for legacy field names.
+func NewCompleteWithCollection(collection patterns.Collection, additionalPatterns ...map[string]string) (*Grok, error) {
+	g, err := NewWithPatterns(collection.All()...)
 	if err != nil {
 		return nil, err
 	}
@@ -149,16 +145,49 @@ func (grok *Grok) HasCaptureGroups() bool {
 	return false
 }
 
-func (grok *Grok) Compile(pattern string, namedCapturesOnly bool) error {
-	return grok.compile(pattern, namedCapturesOnly)
+// CompileOption customizes compilation of a pattern.
+type CompileOption func(*compileConfig)
+
+type compileConfig struct {
+	renames    map[string]string
+	matchFirst bool
+}
+
+// WithRenames renames captured fields at compile time, e.g "source.address" to "client.ip".
+// Fields renamed to an empty string are not captured at all.
+// Renaming a field to a name of another field present in the pattern results in ErrRenameConflict.
+func WithRenames(renames map[string]string) CompileOption {
+	return func(cfg *compileConfig) {
+		cfg.renames = renames
+	}
+}
+
+// WithMatchFirst matches text before extracting submatches, so that text not matching is rejected
+// by the cheaper match. It pays off when most of text does not match, matching text is run through
+// the regular expression twice. The option applies only to expressions requiring no literals
+// and is ignored for expressions requiring literals, text lacking them is rejected before matching
+// and text containing them is submatched right away.
+func WithMatchFirst() CompileOption {
+	return func(cfg *compileConfig) {
+		cfg.matchFirst = true
+	}
+}
+
+func (grok *Grok) Compile(pattern string, namedCapturesOnly bool, opts ...CompileOption) error {
+	var cfg compileConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return grok.compile(pattern, namedCapturesOnly, cfg)
 }
 
 func (grok *Grok) Match(text []byte) bool {
-	return grok.re.Match(text)
+	return grok.mayMatch(text) && grok.re.Match(text)
 }
 
 func (grok *Grok) MatchString(text string) bool {
-	return grok.re.MatchString(text)
+	return grok.mayMatchString(text) && grok.re.MatchString(text)
 }
 
 // ParseString parses text in a form of string and returns map[string]string with values
@@ -201,9 +230,9 @@ func (grok *Grok) ParseTypedString(text string) (map[string]interface{}, error)
 	return grok.ParseTyped([]byte(text))
 }
 
-func (grok *Grok) compile(pattern string, namedCapturesOnly bool) error {
+func (grok *Grok) compile(pattern string, namedCapturesOnly bool, cfg compileConfig) error {
 	// get expanded pattern
-	expandedExpression, hints, err := grok.expand(pattern, namedCapturesOnly)
+	expandedExpression, hints, err := grok.expand(pattern, namedCapturesOnly, cfg.renames)
 	if err != nil {
 		return err
 	}
@@ -213,13 +242,26 @@ func (grok *Grok) compile(pattern string, namedCapturesOnly bool) error {
 		return err
 	}
 
+	literals, err := requiredLiterals(expandedExpression)
+	if err != nil {
+		return err
+	}
+
 	grok.re = compiledExpression
+	grok.setLiterals(literals)
+	grok.matchFirst = cfg.matchFirst && len(literals) == 0
 	grok.typeHints = hints
+	grok.pattern = pattern
+	grok.namedCapturesOnly = namedCapturesOnly
+	grok.cfg = cfg
 
 	return nil
 }
 
 func (grok *Grok) captureString(text string) (map[string]string, error) {
+	if !grok.mayMatchString(text) || grok.matchFirst && !grok.re.MatchString(text) {
+		return make(map[string]string), nil
+	}
 	return captureTypeFn(grok.re, text,
 		func(v, _ string) (string, error) {
 			return v, nil
@@ -228,6 +270,9 @@ func (grok *Grok) captureString(text string) (map[string]string, error) {
 }
 
 func (grok *Grok) captureBytes(text []byte) (map[string][]byte, error) {
+	if !grok.mayMatch(text) || grok.matchFirst && !grok.re.Match(text) {
+		return make(map[string][]byte), nil
+	}
 	return captureTypeFn(grok.re, string(text),
 		func(v, _ string) ([]byte, error) {
 			return []byte(v), nil
@@ -236,13 +281,20 @@ func (grok *Grok) captureBytes(text []byte) (map[string][]byte, error) {
 }
 
 func (grok *Grok) captureTyped(text []byte) (map[string]interface{}, error) {
+	if !grok.mayMatch(text) || grok.matchFirst && !grok.re.Match(text) {
+		return make(map[string]interface{}), nil
+	}
 	return captureTypeFn(grok.re, string(text), grok.convertMatch)
 }
 
 func captureTypeFn[K any](re *regexp.Regexp, text string, conversionFn func(v, key string) (K, error)) (map[string]K, error) {
+	return capturesOf(re, re.FindStringSubmatch(text), conversionFn)
+}
+
+// capturesOf converts submatches of re to captured fields, no submatches result in empty captures.
+func capturesOf[K any](re *regexp.Regexp, matches []string, conversionFn func(v, key string) (K, error)) (map[string]K, error) {
 	captures := make(map[string]K)
 
-	matches := re.FindStringSubmatch(text)
 	if len(matches) == 0 {
 		return captures, nil
 	}
@@ -267,7 +319,7 @@ func captureTypeFn[K any](re *regexp.Regexp, text string, conversionFn func(v, k
 			if err != nil {
 				return nil, err
 			}
-			captures[strings.ReplaceAll(name, dotSep, ".")] = v
+			captures[fieldName(name)] = v
 		}
 	}
 
@@ -280,85 +332,197 @@ func (grok *Grok) convertMatch(match, name string) (interface{}, error) {
 		return match, nil
 	}
 
+	v, err := Convert(match, hint)
+	if errors.Is(err, ErrTypeNotProvided) {
+		return nil, fmt.Errorf("invalid type for %v: %w", name, ErrTypeNotProvided)
+	}
+	return v, err
+}
+
+// Convert converts captured value according to type hint the same way ParseTyped does.
+// Supported hints are string, int, long, float, double, bool and boolean,
+// other hints result in ErrTypeNotProvided.
+func Convert(value, hint string) (interface{}, error) {
 	switch hint {
 	case "string":
-		return match, nil
+		return value, nil
 
 	case "double":
-		return strconv.ParseFloat(match, 64)
+		return strconv.ParseFloat(value, 64)
 	case "float":
-		return strconv.ParseFloat(match, 64)
+		return strconv.ParseFloat(value, 64)
 
 	case "int":
-		return strconv.Atoi(match)
+		return strconv.Atoi(value)
 	case "long":
-		return strconv.Atoi(match)
+		return strconv.Atoi(value)
 
 	case "bool":
-		return strconv.ParseBool(match)
+		return strconv.ParseBool(value)
 	case "boolean":
-		return strconv.ParseBool(match)
+		return strconv.ParseBool(value)
 	default:
-		return nil, fmt.Errorf("invalid type for %v: %w", name, ErrTypeNotProvided)
+		return nil, fmt.Errorf("invalid type %q: %w", hint, ErrTypeNotProvided)
 	}
 }
 
 // expand processes a pattern and returns expanded regular expression, type hints and error
-func (grok *Grok) expand(pattern string, namedCapturesOnly bool) (string, map[string]string, error) {
-	hints := make(map[string]string)
-	expandedPattern := pattern
-
-	// recursion break is guarding against cyclic reference in pattern definitions
-	// as this is performed only once at compile time more clever optimization (e.g detecting cycles in graph) is TBD
-	for recursionBreak := 1000; recursionBreak > 0; recursionBreak-- {
-		subMatches := reusePattern.FindAllStringSubmatch(expandedPattern, -1)
-		if len(subMatches) == 0 {
-			// nothing to expand anymore
-			break
-		}
+// expand expands pattern into a regular expression, capture groups of fields are renamed according to renames.
+func (grok *Grok) expand(pattern string, namedCapturesOnly bool, renames map[string]string) (string, map[string]string, error) {
+	e := expander{
+		grok:              grok,
+		namedCapturesOnly: namedCapturesOnly,
+		renames:           renames,
+		captured:          make(map[string]bool),
+		hints:             make(map[string]string),
+		parsed:            make(map[string]*ast.Expression),
+		visiting:          make(map[string]bool),
+	}
+
+	expression, err := ast.Parse(pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("%v: %w", err, ErrParseFailure)
+	}
+
+	if err := e.writeNodes(expression.Children); err != nil {
+		return "", nil, err
+	}
 
-		for _, nameSubmatch := range subMatches {
-			// grok can be specified in either of these forms:
-			// %{SYNTAX} - e.g {NUMBER}
-			// %{SYNTAX:ID} - e.g {NUMBER:MY_AGE}
-			// %{SYNTAX:ID:TYPE} - e.g {NUMBER:MY_AGE:INT}
-
-			// nameSubmatch is equal to [["%{NAME:ID:TYPe}" "NAME:ID:TYPe"]]
-			// we need only inner part
-			nameParts := strings.Split(nameSubmatch[1], ":")
-
-			grokId := nameParts[0]
-			var targetId string
-			if len(nameParts) > 1 {
-				targetId = strings.ReplaceAll(nameParts[1], ".", dotSep)
-			} else {
-				targetId = nameParts[0]
+	if err := checkRenames(e.captured, renames); err != nil {
+		return "", nil, err
+	}
+
+	return e.sb.String(), e.hints, nil
+}
+
+// expander replaces references with definitions of referenced patterns while walking the expression tree.
+type expander struct {
+	grok              *Grok
+	namedCapturesOnly bool
+	// renames are applied to names of capture groups as they are written
+	renames map[string]string
+	// captured holds names of captured fields before renaming
+	captured map[string]bool
+	sb       strings.Builder
+	hints    map[string]string
+	// parsed caches parsed definitions as the same pattern is usually referenced multiple times
+	parsed map[string]*ast.Expression
+	// visiting holds patterns currently being expanded to detect cyclic references
+	visiting map[string]bool
+}
+
+func (e *expander) writeNodes(nodes []ast.Node) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *ast.Group:
+			switch {
+			case e.namedCapturesOnly && n.Kind == ast.Capturing:
+				// unnamed groups of expression and definitions are not part of results,
+				// capturing them only slows down matching
+				e.sb.WriteString("(?:")
+			case n.Kind == ast.NamedCapturing:
+				e.writeCaptureOpen(n.Name)
+			default:
+				e.sb.WriteString(n.Open)
 			}
-			// compile hints for used patterns
-			if len(nameParts) == 3 {
-				hints[targetId] = nameParts[2]
+			if err := e.writeNodes(n.Children); err != nil {
+				return err
 			}
+			e.sb.WriteString(")")
 
-			knownPattern, found := grok.lookupPattern(grokId)
-			if !found {
-				return "", nil, fmt.Errorf("pattern definition %q unknown: %w", grokId, ErrParseFailure)
+		case *ast.Reference:
+			if err := e.writeReference(n); err != nil {
+				return err
 			}
 
-			var replacementPattern string
-			if namedCapturesOnly && len(nameParts) == 1 {
-				// this has no semantic (pattern:foo) so we don't need to capture
-				replacementPattern = "(" + knownPattern + ")"
+		default:
+			e.sb.WriteString(node.String())
+		}
+	}
 
-			} else {
-				replacementPattern = "(?P<" + targetId + ">" + knownPattern + ")"
-			}
+	return nil
+}
 
-			// expand pattern with definition
-			expandedPattern = strings.ReplaceAll(expandedPattern, nameSubmatch[0], replacementPattern)
+func (e *expander) writeReference(reference *ast.Reference) error {
+	// grok can be specified in either of these forms:
+	// %{SYNTAX} - e.g {NUMBER}
+	// %{SYNTAX:ID} - e.g {NUMBER:MY_AGE}
+	// %{SYNTAX:ID:TYPE} - e.g {NUMBER:MY_AGE:INT}
+	// supported types are int, long, double, float and boolean
+	// for go specific implementation int and long results in int
+	// double and float both results in float
+	targetId := reference.Syntax
+	if reference.ID != "" {
+		targetId = groupName(reference.ID)
+	}
+	captured := !e.namedCapturesOnly || reference.ID != ""
+	if captured {
+		e.captured[fieldName(targetId)] = true
+		if renamed, kept := renamedGroup(targetId, e.renames); kept {
+			targetId = renamed
+		} else {
+			captured = false
+			targetId = ""
 		}
 	}
+	// compile hints for used patterns
+	if reference.Type != "" && targetId != "" {
+		e.hints[targetId] = reference.Type
+	}
+
+	definition, err := e.definition(reference.Syntax)
+	if err != nil {
+		return err
+	}
+
+	if e.visiting[reference.Syntax] {
+		return fmt.Errorf("pattern definition %q references itself: %w", reference.Syntax, ErrParseFailure)
+	}
+	e.visiting[reference.Syntax] = true
+	defer delete(e.visiting, reference.Syntax)
+
+	if !captured {
+		// this has no semantic (pattern:foo) or the field is dropped so we don't need to capture
+		e.sb.WriteString("(?:")
+	} else {
+		e.sb.WriteString("(?P<" + targetId + ">")
+	}
+
+	if err := e.writeNodes(definition.Children); err != nil {
+		return err
+	}
+	e.sb.WriteString(")")
+
+	return nil
+}
+
+// writeCaptureOpen opens a capture group of a named group written in expression or definition.
+func (e *expander) writeCaptureOpen(name string) {
+	e.captured[fieldName(name)] = true
+	if renamed, kept := renamedGroup(name, e.renames); kept {
+		e.sb.WriteString("(?P<" + renamed + ">")
+	} else {
+		e.sb.WriteString("(?:")
+	}
+}
+
+func (e *expander) definition(grokId string) (*ast.Expression, error) {
+	if parsed, found := e.parsed[grokId]; found {
+		return parsed, nil
+	}
+
+	knownPattern, found := e.grok.lookupPattern(grokId)
+	if !found {
+		return nil, fmt.Errorf("pattern definition %q unknown: %w", grokId, ErrParseFailure)
+	}
+
+	parsed, err := ast.Parse(knownPattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern definition %q: %v: %w", grokId, err, ErrParseFailure)
+	}
 
-	return expandedPattern, hints, nil
+	e.parsed[grokId] = parsed
+	return parsed, nil
 }
 
 func (grok *Grok) lookupPattern(grokId string) (string, bool) {
@@ -500,3 +500,159 @@ func TestUnsupportedName(t *testing.T) {
 	err = g.AddPatterns(invalidPatterns)
 	require.Equal(t, err, grok.ErrUnsupportedName)
 }
+
+func TestCompileWithRenames(t *testing.T) {
+	testCases := []struct {
+		Name                 string
+		Pattern              string
+		Renames              map[string]string
+		Text                 string
+		ExpectedTypedMatches map[string]interface{}
+		NamedCapturesOnly    bool
+	}{
+		{
+			"rename keeps type hint",
+			"%{IP:source.address} %{NUMBER:source.port:int}",
+			map[string]string{"source.address": "client.ip", "source.port": "client.port"},
+			"127.0.0.1 1234",
+			map[string]interface{}{
+				"client.ip":   "127.0.0.1",
+				"client.port": 1234,
+			},
+			true,
+		},
+		{
+			"drop field",
+			"%{IP:source.address} %{NUMBER:source.port:int}",
+			map[string]string{"source.port": ""},
+			"127.0.0.1 1234",
+			map[string]interface{}{
+				"source.address": "127.0.0.1",
+			},
+			true,
+		},
+		{
+			"swap fields",
+			"%{IP:source.address} %{IP:destination.address}",
+			map[string]string{"source.address": "destination.address", "destination.address": "source.address"},
+			"127.0.0.1 10.0.0.1",
+			map[string]interface{}{
+				"source.address":      "10.0.0.1",
+				"destination.address": "127.0.0.1",
+			},
+			true,
+		},
+		{
+			"rename unnamed capture",
+			"%{IP} %{WORD:verb}",
+			map[string]string{"IP": "client.ip", "IPV4": "", "verb": "http.request.method"},
+			"127.0.0.1 GET",
+			map[string]interface{}{
+				"client.ip":           "127.0.0.1",
+				"http.request.method": "GET",
+			},
+			false,
+		},
+		{
+			"rename explicit named group",
+			`(?P<source___address>\S+) %{WORD:verb}`,
+			map[string]string{"source.address": "client.ip"},
+			"127.0.0.1 GET",
+			map[string]interface{}{
+				"client.ip": "127.0.0.1",
+				"verb":      "GET",
+			},
+			true,
+		},
+		{
+			"unknown source is ignored",
+			"%{WORD:verb}",
+			map[string]string{"missing": "other"},
+			"GET",
+			map[string]interface{}{
+				"verb": "GET",
+			},
+			true,
+		},
+		{
+			"unknown source renamed to existing field is ignored",
+			"%{WORD:verb}",
+			map[string]string{"missing": "verb"},
+			"GET",
+			map[string]interface{}{
+				"verb": "GET",
+			},
+			true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			g := grok.New()
+			require.NoError(t, g.Compile(tt.Pattern, tt.NamedCapturesOnly, grok.WithRenames(tt.Renames)))
+
+			res, err := g.ParseTypedString(tt.Text)
+			require.NoError(t, err)
+			require.Equal(t, tt.ExpectedTypedMatches, res)
+		})
+	}
+}
+
+func TestCompileWithRenamesExpansion(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	// renamed expression is compiled as expanded, not reformatted
+	require.NoError(t, g.Compile(`%{IP:source.address} \[%{HTTPDATE:timestamp}\]`, true, grok.WithRenames(map[string]string{"source.address": "client.ip"})))
+	expansion, err := g.Expand(`%{IP:client.ip} \[%{HTTPDATE:timestamp}\]`, true)
+	require.NoError(t, err)
+
+	explanation, err := g.Explain("127.0.0.1 [10/Oct/2000:13:55:36 -0700]")
+	require.NoError(t, err)
+	require.True(t, explanation.Matched)
+	require.Equal(t, expansion.Regex, explanation.Expression)
+}
+
+func TestCompileWithRenamesConflict(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Pattern string
+		Renames map[string]string
+	}{
+		{"target exists", "%{IP:source.address} %{IP:destination.address}", map[string]string{"source.address": "destination.address"}},
+		{"target kept by itself", "%{IP:source.address} %{IP:destination.address}", map[string]string{"source.address": "destination.address", "destination.address": "destination.address"}},
+		{"same target twice", "%{IP:source.address} %{IP:destination.address}", map[string]string{"source.address": "ip", "destination.address": "ip"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			g := grok.New()
+			err := g.Compile(tt.Pattern, true, grok.WithRenames(tt.Renames))
+			require.ErrorIs(t, err, grok.ErrRenameConflict)
+		})
+	}
+}
+
+func TestCompileInvalidExpression(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Patterns map[string]string
+		Pattern  string
+	}{
+		{"unknown pattern", nil, "%{MISSING}"},
+		{"cyclic reference", map[string]string{"A": "a%{B}", "B": "(b|%{A})"}, "%{A}"},
+		{"self reference", map[string]string{"A": "a%{A}?"}, "%{A:a}"},
+		{"unbalanced group in definition", map[string]string{"A": "(a"}, "%{A}"},
+		{"unbalanced group in expression", nil, "%{WORD})"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			g, err := grok.NewWithPatterns(tt.Patterns)
+			require.NoError(t, err)
+
+			err = g.Compile(tt.Pattern, true)
+			require.ErrorIs(t, err, grok.ErrParseFailure)
+		})
+	}
+}
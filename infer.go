@@ -0,0 +1,268 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-grok/ast"
+)
+
+// timestampLayouts are layouts recognized by InferTypes, covering timestamps of bundled patterns.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999999999",
+	"02/Jan/2006:15:04:05 -0700",
+	time.Stamp,
+	time.StampMicro,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.ANSIC,
+}
+
+// TypeHint is a type suggested for a captured field.
+type TypeHint struct {
+	Field string
+	// Type is one of long, float, boolean, timestamp, ip and string.
+	Type string
+	// Current is the hint field is compiled with, empty when not set.
+	Current string
+	// Samples is the number of non empty values observed.
+	Samples int
+}
+
+// TypeInference is the result of InferTypes.
+type TypeInference struct {
+	// Hints are suggested types of fields sorted by field name.
+	Hints []TypeHint
+	// Expression is the compiled expression with suggested hints applied.
+	Expression string
+	// Definitions are updated definitions of referenced patterns capturing hinted fields.
+	// Add them to the grok instance to apply hints of fields not captured by the expression directly.
+	Definitions map[string]string
+}
+
+// InferTypes runs compiled expression over lines and suggests type hints of captured fields:
+// long when all values are integral, float when decimal, boolean for true/false values,
+// timestamp and ip for parseable timestamps and IP addresses, string otherwise.
+// Only hints supported by ParseTyped (long, float and boolean) are applied to fields without a hint.
+func (grok *Grok) InferTypes(lines []string) (*TypeInference, error) {
+	// precompiled parsers have no source expression
+	if grok == nil || grok.re == nil || grok.precompiled {
+		return nil, ErrNotCompiled
+	}
+
+	inferences := make(map[string]*typeInference)
+	for _, line := range lines {
+		captures, err := grok.captureString(line)
+		if err != nil {
+			return nil, err
+		}
+
+		for field, value := range captures {
+			inference, found := inferences[field]
+			if !found {
+				inference = newTypeInference()
+				inferences[field] = inference
+			}
+			inference.observe(value)
+		}
+	}
+
+	// hints are applied to references named before renaming
+	sources := make(map[string]string, len(grok.cfg.renames))
+	for source, target := range grok.cfg.renames {
+		sources[target] = source
+	}
+
+	result := &TypeInference{Definitions: make(map[string]string)}
+	apply := make(map[string]string)
+	for field, inference := range inferences {
+		hint := TypeHint{
+			Field:   field,
+			Type:    inference.suggest(),
+			Current: grok.typeHints[groupName(field)],
+			Samples: inference.samples,
+		}
+		result.Hints = append(result.Hints, hint)
+
+		if hint.Current == "" && (hint.Type == "long" || hint.Type == "float" || hint.Type == "boolean") {
+			source := field
+			if s, found := sources[field]; found {
+				source = s
+			}
+			apply[source] = hint.Type
+		}
+	}
+	sort.Slice(result.Hints, func(i, j int) bool {
+		return result.Hints[i].Field < result.Hints[j].Field
+	})
+
+	expression, err := withHints(grok.pattern, apply)
+	if err != nil {
+		return nil, err
+	}
+	result.Expression = expression
+
+	// fields not captured directly by the expression are hinted in definitions of referenced patterns
+	for _, name := range grok.referencedPatterns() {
+		definition, _ := grok.lookupPattern(name)
+		hinted, err := withHints(definition, apply)
+		if err != nil {
+			return nil, err
+		}
+		if hinted != definition {
+			result.Definitions[name] = hinted
+		}
+	}
+
+	return result, nil
+}
+
+// withHints adds type hints to references capturing fields without a hint.
+func withHints(expression string, apply map[string]string) (string, error) {
+	parsed, err := ast.Parse(expression)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, reference := range ast.References(parsed) {
+		hint, found := apply[reference.ID]
+		if reference.ID == "" || reference.Type != "" || !found {
+			continue
+		}
+
+		sb.WriteString(expression[last:reference.Pos()])
+		sb.WriteString("%{" + reference.Syntax + ":" + reference.ID + ":" + hint + "}")
+		last = reference.End()
+	}
+	sb.WriteString(expression[last:])
+
+	return sb.String(), nil
+}
+
+// referencedPatterns returns names of patterns referenced by compiled expression, directly or indirectly,
+// in breadth first order.
+func (grok *Grok) referencedPatterns() []string {
+	var names []string
+	visited := make(map[string]bool)
+
+	queue := []string{grok.pattern}
+	for len(queue) > 0 {
+		parsed, err := ast.Parse(queue[0])
+		queue = queue[1:]
+		if err != nil {
+			continue
+		}
+
+		for _, reference := range ast.References(parsed) {
+			if visited[reference.Syntax] {
+				continue
+			}
+			visited[reference.Syntax] = true
+
+			definition, found := grok.lookupPattern(reference.Syntax)
+			if !found {
+				continue
+			}
+			names = append(names, reference.Syntax)
+			queue = append(queue, definition)
+		}
+	}
+
+	return names
+}
+
+// typeInference narrows down types matching all observed values of a field.
+type typeInference struct {
+	samples   int
+	boolean   bool
+	long      bool
+	float     bool
+	ip        bool
+	timestamp []string
+}
+
+func newTypeInference() *typeInference {
+	return &typeInference{
+		boolean:   true,
+		long:      true,
+		float:     true,
+		ip:        true,
+		timestamp: timestampLayouts,
+	}
+}
+
+func (t *typeInference) observe(value string) {
+	t.samples++
+
+	if t.boolean {
+		// ParseBool accepts also 1, 0, t and f which are rather numbers and letters in logs
+		switch value {
+		case "true", "True", "TRUE", "false", "False", "FALSE":
+		default:
+			t.boolean = false
+		}
+	}
+	if t.long {
+		_, err := strconv.ParseInt(value, 10, 64)
+		t.long = err == nil
+	}
+	if t.float {
+		// NaN and Inf are rather words than numbers in logs
+		_, err := strconv.ParseFloat(value, 64)
+		t.float = err == nil && strings.ContainsAny(value, "0123456789")
+	}
+	if t.ip {
+		t.ip = net.ParseIP(value) != nil
+	}
+
+	var layouts []string
+	for _, layout := range t.timestamp {
+		if _, err := time.Parse(layout, value); err == nil {
+			layouts = append(layouts, layout)
+		}
+	}
+	t.timestamp = layouts
+}
+
+func (t *typeInference) suggest() string {
+	switch {
+	case t.boolean:
+		return "boolean"
+	case t.long:
+		return "long"
+	case t.float:
+		return "float"
+	case t.ip:
+		return "ip"
+	case len(t.timestamp) > 0:
+		return "timestamp"
+	default:
+		return "string"
+	}
+}
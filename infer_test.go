@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestInferTypes(t *testing.T) {
+	g, err := grok.NewWithPatterns(map[string]string{
+		"REQUEST": `%{WORD:method} %{NUMBER:status} %{NUMBER:bytes:long}`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, g.Compile(`%{IP:client} %{DATA:ts} %{WORD:cached} %{NUMBER:duration} %{WORD:user} %{REQUEST}`, true))
+
+	inference, err := g.InferTypes([]string{
+		`10.0.0.1 2024-01-02T03:04:05Z true 0.5 alice GET 200 12`,
+		`::1 2024-01-02T03:04:06.5Z false 12 bob POST 404 0`,
+		`not matching`,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []grok.TypeHint{
+		{Field: "bytes", Type: "long", Current: "long", Samples: 2},
+		{Field: "cached", Type: "boolean", Samples: 2},
+		{Field: "client", Type: "ip", Samples: 2},
+		{Field: "duration", Type: "float", Samples: 2},
+		{Field: "method", Type: "string", Samples: 2},
+		{Field: "status", Type: "long", Samples: 2},
+		{Field: "ts", Type: "timestamp", Samples: 2},
+		{Field: "user", Type: "string", Samples: 2},
+	}, inference.Hints)
+
+	require.Equal(t, `%{IP:client} %{DATA:ts} %{WORD:cached:boolean} %{NUMBER:duration:float} %{WORD:user} %{REQUEST}`, inference.Expression)
+	require.Equal(t, map[string]string{
+		"REQUEST": `%{WORD:method} %{NUMBER:status:long} %{NUMBER:bytes:long}`,
+	}, inference.Definitions)
+
+	// applying suggestions results in typed values
+	require.NoError(t, g.AddPatterns(inference.Definitions))
+	require.NoError(t, g.Compile(inference.Expression, true))
+
+	values, err := g.ParseTypedString(`10.0.0.1 2024-01-02T03:04:05Z true 0.5 alice GET 200 12`)
+	require.NoError(t, err)
+	require.Equal(t, true, values["cached"])
+	require.Equal(t, 0.5, values["duration"])
+	require.Equal(t, 200, values["status"])
+}
+
+func TestInferTypesWithRenames(t *testing.T) {
+	g := grok.New()
+	require.NoError(t, g.Compile(`%{NUMBER:size} %{WORD:name}`, true, grok.WithRenames(map[string]string{"size": "file.size"})))
+
+	inference, err := g.InferTypes([]string{"12 a", "13 b"})
+	require.NoError(t, err)
+	require.Equal(t, "file.size", inference.Hints[0].Field)
+	require.Equal(t, "long", inference.Hints[0].Type)
+	require.Equal(t, `%{NUMBER:size:long} %{WORD:name}`, inference.Expression)
+	require.Empty(t, inference.Definitions)
+
+	_, err = grok.New().InferTypes(nil)
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+}
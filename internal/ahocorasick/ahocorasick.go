@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package ahocorasick finds occurrences of many literal patterns in text in a single pass.
+package ahocorasick
+
+// Automaton is an Aho-Corasick automaton over bytes of patterns, it is safe for concurrent use.
+type Automaton struct {
+	nodes    []node
+	patterns int
+}
+
+type node struct {
+	// edges are transitions of the trie sorted by byte
+	edges []edge
+	// fail is the node of the longest proper suffix of this node present in the trie
+	fail int32
+	// output is the pattern ending at this node, -1 when none
+	output int32
+	// dict is the nearest node reachable by failure links with an output, -1 when none
+	dict int32
+}
+
+type edge struct {
+	b    byte
+	next int32
+}
+
+// New builds automaton finding patterns, patterns are identified by their index.
+// Empty patterns are found in every text, equal patterns are reported by the first index.
+func New(patterns []string) *Automaton {
+	a := &Automaton{nodes: []node{{output: -1, dict: -1}}, patterns: len(patterns)}
+
+	for i, pattern := range patterns {
+		current := int32(0)
+		for j := 0; j < len(pattern); j++ {
+			next := a.child(current, pattern[j])
+			if next < 0 {
+				next = int32(len(a.nodes))
+				a.nodes = append(a.nodes, node{output: -1, dict: -1})
+				a.insertEdge(current, pattern[j], next)
+			}
+			current = next
+		}
+		if a.nodes[current].output < 0 {
+			a.nodes[current].output = int32(i)
+		}
+	}
+
+	// failure links are set in breadth first order, failure of a node is always shallower
+	queue := make([]int32, 0, len(a.nodes))
+	for _, e := range a.nodes[0].edges {
+		queue = append(queue, e.next)
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, e := range a.nodes[current].edges {
+			fail := a.nodes[current].fail
+			for fail > 0 && a.child(fail, e.b) < 0 {
+				fail = a.nodes[fail].fail
+			}
+			if next := a.child(fail, e.b); next >= 0 && next != e.next {
+				fail = next
+			} else {
+				fail = 0
+			}
+
+			a.nodes[e.next].fail = fail
+			if a.nodes[fail].output >= 0 {
+				a.nodes[e.next].dict = fail
+			} else {
+				a.nodes[e.next].dict = a.nodes[fail].dict
+			}
+			queue = append(queue, e.next)
+		}
+	}
+
+	return a
+}
+
+// Len returns the number of patterns.
+func (a *Automaton) Len() int {
+	return a.patterns
+}
+
+// Find calls found for every pattern present in text, each pattern is reported once.
+// seen is scratch space of at least Len elements marking reported patterns, it is cleared first
+// so that it can be reused for every text without allocating.
+func (a *Automaton) Find(text string, seen []bool, found func(pattern int)) {
+	seen = seen[:a.patterns]
+	clear(seen)
+	report := func(n int32) {
+		for ; n >= 0; n = a.nodes[n].dict {
+			if output := a.nodes[n].output; output >= 0 && !seen[output] {
+				seen[output] = true
+				found(int(output))
+			}
+		}
+	}
+
+	// empty pattern ends at the root
+	report(0)
+
+	current := int32(0)
+	for i := 0; i < len(text); i++ {
+		next := a.child(current, text[i])
+		for next < 0 && current > 0 {
+			current = a.nodes[current].fail
+			next = a.child(current, text[i])
+		}
+		if next < 0 {
+			continue
+		}
+		current = next
+		report(current)
+	}
+}
+
+func (a *Automaton) child(n int32, b byte) int32 {
+	edges := a.nodes[n].edges
+	// binary search as nodes close to the root have many edges
+	lo, hi := 0, len(edges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case edges[mid].b == b:
+			return edges[mid].next
+		case edges[mid].b < b:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return -1
+}
+
+func (a *Automaton) insertEdge(n int32, b byte, next int32) {
+	edges := a.nodes[n].edges
+	i := 0
+	for i < len(edges) && edges[i].b < b {
+		i++
+	}
+	edges = append(edges, edge{})
+	copy(edges[i+1:], edges[i:])
+	edges[i] = edge{b: b, next: next}
+	a.nodes[n].edges = edges
+}
@@ -0,0 +1,105 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ahocorasick_test
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok/internal/ahocorasick"
+)
+
+func find(a *ahocorasick.Automaton, text string) []int {
+	found := []int{}
+	a.Find(text, make([]bool, a.Len()), func(pattern int) {
+		found = append(found, pattern)
+	})
+	sort.Ints(found)
+	return found
+}
+
+func TestFind(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Patterns []string
+		Text     string
+		Expected []int
+	}{
+		{"classic", []string{"he", "she", "his", "hers"}, "ushers", []int{0, 1, 3}},
+		{"none", []string{"abc", "xyz"}, "abxy", []int{}},
+		{"overlapping suffixes", []string{"a", "aa", "aaa"}, "aa", []int{0, 1}},
+		{"reported once", []string{"ab"}, "ababab", []int{0}},
+		{"empty pattern", []string{"", "x"}, "abc", []int{0}},
+		{"duplicate pattern", []string{"ab", "ab"}, "ab", []int{0}},
+		{"failure into longer pattern", []string{"abcd", "bcx"}, "abcx", []int{1}},
+		{"empty text", []string{"a"}, "", []int{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Expected, find(ahocorasick.New(tc.Patterns), tc.Text))
+		})
+	}
+}
+
+func TestFindReusedSeen(t *testing.T) {
+	a := ahocorasick.New([]string{"he", "she"})
+	seen := make([]bool, a.Len())
+
+	for _, text := range []string{"she", "she", "he"} {
+		var found []int
+		a.Find(text, seen, func(pattern int) {
+			found = append(found, pattern)
+		})
+		require.NotEmpty(t, found, text)
+	}
+}
+
+func TestFindRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = "abc"[r.Intn(3)]
+		}
+		return string(b)
+	}
+
+	for i := 0; i < 200; i++ {
+		patterns := make([]string, 1+r.Intn(20))
+		for j := range patterns {
+			patterns[j] = random(1 + r.Intn(5))
+		}
+		text := random(r.Intn(40))
+
+		expected := []int{}
+		seen := make(map[string]bool)
+		for j, pattern := range patterns {
+			if !seen[pattern] && strings.Contains(text, pattern) {
+				expected = append(expected, j)
+			}
+			seen[pattern] = true
+		}
+
+		require.Equal(t, expected, find(ahocorasick.New(patterns), text), "patterns %q, text %q", patterns, text)
+	}
+}
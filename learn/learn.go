@@ -0,0 +1,424 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package learn mines grok expressions from a corpus of log lines.
+//
+// Lines are clustered into templates using a fixed depth prefix tree as described by Drain
+// (He et al., "Drain: An Online Log Parsing Approach with Fixed Depth Tree"),
+// variable positions of every template are then generalised into bundled grok patterns
+// and resulting expressions are verified against the corpus.
+package learn
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elastic/go-grok"
+)
+
+const wildcard = "<*>"
+
+// DefaultPatterns lists patterns used to generalise variable tokens, most specific first.
+// Values not matched by any of them are generalised as DATA.
+var DefaultPatterns = []string{
+	"TIMESTAMP_ISO8601",
+	"UUID",
+	"IP",
+	"NUMBER",
+	"IPORHOST",
+	"NOTSPACE",
+}
+
+// Options configures the learner.
+type Options struct {
+	// Depth is the depth of the prefix tree including root and token count layers, 4 when not set.
+	// Lines sharing first Depth-2 tokens are compared for similarity.
+	Depth int
+	// Similarity is the minimal ratio of equal tokens for a line to join a cluster, 0.4 when not set.
+	Similarity float64
+	// MaxChildren limits children of a tree node, further tokens are routed through a wildcard, 100 when not set.
+	MaxChildren int
+	// Patterns lists patterns used to generalise variable tokens, most specific first.
+	// DefaultPatterns are used when empty.
+	Patterns []string
+	// Definitions are additional pattern definitions available to expressions.
+	Definitions []map[string]string
+	// Fields names captured fields after lower cased pattern names, e.g %{NUMBER:number} and %{NUMBER:number_2}.
+	// References are not captured otherwise.
+	Fields bool
+}
+
+// Template is a learned grok expression.
+type Template struct {
+	// Expression is an anchored grok expression matching lines of the cluster.
+	Expression string
+	// Lines is the number of lines clustered into the template.
+	Lines int
+	// Matched is the number of clustered lines matched by Expression.
+	Matched int
+	// Coverage is the ratio of all lines of the corpus matched by Expression.
+	Coverage float64
+	// Example is the first line of the cluster.
+	Example string
+}
+
+// Learn clusters lines into templates and returns grok expressions ranked by number of clustered lines.
+func Learn(lines []string, opts Options) ([]Template, error) {
+	opts = withDefaults(opts)
+
+	g, err := grok.NewWithPatterns(opts.Definitions...)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := compilePatterns(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := clusterLines(lines, opts)
+
+	templates := make([]Template, 0, len(clusters))
+	for _, c := range clusters {
+		expression, err := generalise(c, patterns, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		template, err := verify(g, expression, c, lines)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].Lines != templates[j].Lines {
+			return templates[i].Lines > templates[j].Lines
+		}
+		return templates[i].Coverage > templates[j].Coverage
+	})
+
+	return templates, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Depth < 3 {
+		opts.Depth = 4
+	}
+	if opts.Similarity <= 0 {
+		opts.Similarity = 0.4
+	}
+	if opts.MaxChildren <= 0 {
+		opts.MaxChildren = 100
+	}
+	if len(opts.Patterns) == 0 {
+		opts.Patterns = DefaultPatterns
+	}
+	return opts
+}
+
+type cluster struct {
+	template []string
+	// variable marks template positions where lines differ
+	variable []bool
+	lines    [][]string
+	raw      []string
+}
+
+type node struct {
+	children map[string]*node
+	clusters []*cluster
+}
+
+func clusterLines(lines []string, opts Options) []*cluster {
+	var clusters []*cluster
+	root := make(map[int]*node)
+
+	for _, line := range lines {
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		leaf := route(root, tokens, opts)
+		if c := bestCluster(leaf.clusters, tokens, opts.Similarity); c != nil {
+			c.merge(tokens, line)
+			continue
+		}
+
+		c := &cluster{
+			template: append([]string(nil), tokens...),
+			variable: make([]bool, len(tokens)),
+			lines:    [][]string{tokens},
+			raw:      []string{line},
+		}
+		leaf.clusters = append(leaf.clusters, c)
+		clusters = append(clusters, c)
+	}
+
+	return clusters
+}
+
+// route walks the prefix tree by token count and leading tokens, creating missing nodes.
+func route(root map[int]*node, tokens []string, opts Options) *node {
+	current, found := root[len(tokens)]
+	if !found {
+		current = &node{children: make(map[string]*node)}
+		root[len(tokens)] = current
+	}
+
+	for i := 0; i < opts.Depth-2 && i < len(tokens); i++ {
+		key := tokens[i]
+		if hasDigit(key) {
+			key = wildcard
+		}
+
+		child, found := current.children[key]
+		if !found && len(current.children) >= opts.MaxChildren {
+			key = wildcard
+			child, found = current.children[key]
+		}
+		if !found {
+			child = &node{children: make(map[string]*node)}
+			current.children[key] = child
+		}
+		current = child
+	}
+
+	return current
+}
+
+// bestCluster returns the most similar cluster, nil when none reaches the threshold.
+func bestCluster(clusters []*cluster, tokens []string, threshold float64) *cluster {
+	var best *cluster
+	bestSimilarity, bestVariables := -1.0, -1
+
+	for _, c := range clusters {
+		equal, variables := 0, 0
+		for i, token := range tokens {
+			switch {
+			case c.variable[i]:
+				variables++
+			case c.template[i] == token:
+				equal++
+			}
+		}
+
+		similarity := float64(equal) / float64(len(tokens))
+		if similarity > bestSimilarity || (similarity == bestSimilarity && variables > bestVariables) {
+			best, bestSimilarity, bestVariables = c, similarity, variables
+		}
+	}
+
+	if best == nil || bestSimilarity < threshold {
+		return nil
+	}
+	return best
+}
+
+func (c *cluster) merge(tokens []string, line string) {
+	for i, token := range tokens {
+		if c.template[i] != token {
+			c.variable[i] = true
+		}
+	}
+	c.lines = append(c.lines, tokens)
+	c.raw = append(c.raw, line)
+}
+
+// generalise builds grok expression of a cluster, variable tokens are replaced by patterns
+// matching all values seen at the position while common prefix and suffix are kept literal.
+func generalise(c *cluster, patterns []valuePattern, opts Options) (string, error) {
+	parts := make([]string, len(c.template))
+	used := make(map[string]int)
+
+	for i, token := range c.template {
+		if !c.variable[i] {
+			parts[i] = regexp.QuoteMeta(token)
+			continue
+		}
+
+		values := make([]string, len(c.lines))
+		for j, tokens := range c.lines {
+			values[j] = tokens[i]
+		}
+
+		pattern := matchingPattern(values, patterns)
+
+		// literal prefix and suffix are kept only when the rest is matched by a more specific pattern,
+		// e.g user=%{IPORHOST} for user=alice and user=bob
+		prefix, suffix := commonAffixes(values)
+		if prefix != "" || suffix != "" {
+			inner := make([]string, len(values))
+			for j, value := range values {
+				inner[j] = value[len(prefix) : len(value)-len(suffix)]
+			}
+
+			innerPattern := matchingPattern(inner, patterns)
+
+			if specificity(innerPattern, opts.Patterns) < specificity(pattern, opts.Patterns) {
+				pattern = innerPattern
+			} else {
+				prefix, suffix = "", ""
+			}
+		}
+
+		reference := "%{" + pattern + "}"
+		if opts.Fields {
+			field := strings.ToLower(pattern)
+			used[field]++
+			if n := used[field]; n > 1 {
+				field += "_" + strconv.Itoa(n)
+			}
+			reference = "%{" + pattern + ":" + field + "}"
+		}
+
+		parts[i] = regexp.QuoteMeta(prefix) + reference + regexp.QuoteMeta(suffix)
+	}
+
+	return "^" + strings.Join(parts, `\s+`) + "$", nil
+}
+
+// valuePattern is a pattern of Options.Patterns compiled to match values as a whole.
+type valuePattern struct {
+	name string
+	g    *grok.Grok
+}
+
+// compilePatterns compiles patterns values are generalised to once for all clusters.
+func compilePatterns(opts Options) ([]valuePattern, error) {
+	patterns := make([]valuePattern, len(opts.Patterns))
+	for i, name := range opts.Patterns {
+		g, err := grok.NewWithPatterns(opts.Definitions...)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.Compile("^%{"+name+"}$", true); err != nil {
+			return nil, err
+		}
+		patterns[i] = valuePattern{name: name, g: g}
+	}
+	return patterns, nil
+}
+
+// matchingPattern returns the first pattern matching all values as a whole, DATA when none does.
+func matchingPattern(values []string, patterns []valuePattern) string {
+	for _, pattern := range patterns {
+		matchesAll := true
+		for _, value := range values {
+			if !pattern.g.MatchString(value) {
+				matchesAll = false
+				break
+			}
+		}
+		if matchesAll {
+			return pattern.name
+		}
+	}
+
+	return "DATA"
+}
+
+// specificity returns position of pattern in patterns, DATA is the least specific one.
+func specificity(pattern string, patterns []string) int {
+	for i, p := range patterns {
+		if p == pattern {
+			return i
+		}
+	}
+	return len(patterns)
+}
+
+// commonAffixes returns prefix and suffix shared by all values which end, respectively start,
+// with a non word character, e.g "user=" and "" for "user=alice" and "user=bob".
+func commonAffixes(values []string) (string, string) {
+	prefix, suffix := values[0], values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+		for !strings.HasSuffix(value, suffix) {
+			suffix = suffix[1:]
+		}
+	}
+
+	// byte wise comparison may split a multi byte character
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	for !utf8.ValidString(suffix) {
+		suffix = suffix[1:]
+	}
+
+	prefix = prefix[:strings.LastIndexFunc(prefix, isNonWord)+1]
+	if i := strings.IndexFunc(suffix, isNonWord); i >= 0 {
+		suffix = suffix[i:]
+	} else {
+		suffix = ""
+	}
+
+	// affixes must not overlap in the shortest value
+	for _, value := range values {
+		if len(prefix)+len(suffix) > len(value) {
+			return prefix, ""
+		}
+	}
+
+	return prefix, suffix
+}
+
+func verify(g *grok.Grok, expression string, c *cluster, lines []string) (Template, error) {
+	if err := g.Compile(expression, true); err != nil {
+		return Template{}, err
+	}
+
+	template := Template{
+		Expression: expression,
+		Lines:      len(c.lines),
+		Example:    c.raw[0],
+	}
+
+	for _, line := range c.raw {
+		if g.MatchString(line) {
+			template.Matched++
+		}
+	}
+
+	var covered int
+	for _, line := range lines {
+		if g.MatchString(line) {
+			covered++
+		}
+	}
+	if len(lines) > 0 {
+		template.Coverage = float64(covered) / float64(len(lines))
+	}
+
+	return template, nil
+}
+
+func hasDigit(token string) bool {
+	return strings.ContainsAny(token, "0123456789")
+}
+
+func isNonWord(r rune) bool {
+	return !(r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+}
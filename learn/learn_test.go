@@ -0,0 +1,113 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package learn_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/learn"
+)
+
+var corpus = []string{
+	"2024-01-02T03:04:05Z INFO user=alice logged in from 10.0.0.1",
+	"2024-01-02T03:04:06Z INFO user=bob logged in from 10.0.0.2",
+	"2024-01-02T03:04:07Z INFO user=carol logged in from host-a.example.com",
+	"2024-01-02T03:04:08Z WARN disk usage 91% on /dev/sda1",
+	"2024-01-02T03:04:09Z WARN disk usage 95% on /dev/sdb1",
+	"connection closed id=123",
+	"connection closed id=124",
+	"connection reset id=5",
+}
+
+func TestLearn(t *testing.T) {
+	templates, err := learn.Learn(corpus, learn.Options{})
+	require.NoError(t, err)
+
+	require.Equal(t, []learn.Template{
+		{
+			Expression: `^%{TIMESTAMP_ISO8601}\s+INFO\s+user=%{IPORHOST}\s+logged\s+in\s+from\s+%{IPORHOST}$`,
+			Lines:      3,
+			Matched:    3,
+			Coverage:   0.375,
+			Example:    corpus[0],
+		},
+		{
+			Expression: `^%{TIMESTAMP_ISO8601}\s+WARN\s+disk\s+usage\s+%{NUMBER}%\s+on\s+/dev/%{IPORHOST}$`,
+			Lines:      2,
+			Matched:    2,
+			Coverage:   0.25,
+			Example:    corpus[3],
+		},
+		{
+			Expression: `^connection\s+closed\s+id=%{NUMBER}$`,
+			Lines:      2,
+			Matched:    2,
+			Coverage:   0.25,
+			Example:    corpus[5],
+		},
+		{
+			Expression: `^connection\s+reset\s+id=5$`,
+			Lines:      1,
+			Matched:    1,
+			Coverage:   0.125,
+			Example:    corpus[7],
+		},
+	}, templates)
+}
+
+func TestLearn_Options(t *testing.T) {
+	templates, err := learn.Learn(corpus[5:], learn.Options{Depth: 3, Similarity: 0.3, Fields: true})
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, `^connection\s+%{IPORHOST:iporhost}\s+id=%{NUMBER:number}$`, templates[0].Expression)
+	require.Equal(t, 1.0, templates[0].Coverage)
+
+	templates, err = learn.Learn(corpus[:3], learn.Options{Similarity: 0.9})
+	require.NoError(t, err)
+	require.Len(t, templates, 3)
+
+	templates, err = learn.Learn(corpus[:2], learn.Options{Patterns: []string{"WORD"}})
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, `^2024-01-02T03:04:%{WORD}\s+INFO\s+user=%{WORD}\s+logged\s+in\s+from\s+10\.0\.0\.%{WORD}$`, templates[0].Expression)
+}
+
+func TestLearn_Verified(t *testing.T) {
+	lines := append(append([]string(nil), corpus...),
+		`10.1.2.3 - - [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.0" 200 2326`,
+		`10.1.2.4 - - [10/Oct/2000:13:55:37 -0700] "GET /about.html HTTP/1.0" 404 12`,
+		`tab	separated   and  spaced`,
+	)
+
+	templates, err := learn.Learn(lines, learn.Options{Fields: true})
+	require.NoError(t, err)
+
+	var total int
+	for _, template := range templates {
+		require.Equal(t, template.Lines, template.Matched, template.Expression)
+		total += template.Lines
+
+		g := grok.New()
+		require.NoError(t, g.Compile(template.Expression, true))
+		require.True(t, g.MatchString(template.Example))
+	}
+	require.Equal(t, len(lines), total)
+}
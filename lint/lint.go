@@ -0,0 +1,304 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package lint analyses grok pattern definitions and reports common authoring mistakes.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/ast"
+)
+
+// Check identifies kind of a finding.
+type Check string
+
+const (
+	// UnescapedDot reports '.' matching any character where a literal dot was probably intended.
+	UnescapedDot Check = "unescaped-dot"
+	// CharClassRange reports character class ranges spanning unrelated characters, e.g [+-=].
+	CharClassRange Check = "char-class-range"
+	// TopLevelAlternation reports definitions with '|' outside of any group.
+	TopLevelAlternation Check = "top-level-alternation"
+	// GreedyInMiddle reports GREEDYDATA followed by further expressions.
+	GreedyInMiddle Check = "greedy-in-middle"
+	// Unused reports definitions not reachable from any entry point.
+	Unused Check = "unused"
+	// Shadowed reports definitions overwritten by a later set.
+	Shadowed Check = "shadowed"
+	// EmptyMatch reports definitions which can match an empty string.
+	EmptyMatch Check = "empty-match"
+	// UnknownReference reports references to undefined patterns.
+	UnknownReference Check = "unknown-reference"
+	// MalformedReference reports '%{' not forming a valid reference, which is then matched literally.
+	MalformedReference Check = "malformed-reference"
+	// Invalid reports definitions which do not compile.
+	Invalid Check = "invalid"
+)
+
+// Finding is a single issue found in a pattern definition.
+type Finding struct {
+	// Pattern is the name of definition the finding is tied to.
+	Pattern string
+	Check   Check
+	Message string
+	// Offset is a byte offset within the definition, -1 when finding applies to the whole definition.
+	Offset int
+}
+
+func (f Finding) String() string {
+	if f.Offset < 0 {
+		return fmt.Sprintf("%s: %s: %s", f.Pattern, f.Check, f.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", f.Pattern, f.Offset, f.Check, f.Message)
+}
+
+// Options configures the linter.
+type Options struct {
+	// EntryPoints are definitions used directly by expressions.
+	// When not empty, definitions not reachable from any entry point are reported as unused.
+	EntryPoints []string
+}
+
+// Run analyses pattern sets and returns findings sorted by pattern name and offset.
+// Sets are layered as with grok.AddPatterns, definitions from later sets overwrite earlier ones.
+func Run(opts Options, sets ...map[string]string) []Finding {
+	registry := make(map[string]string)
+	var findings []Finding
+
+	for i, set := range sets {
+		for name, definition := range set {
+			if previous, found := registry[name]; found && previous != definition {
+				findings = append(findings, Finding{
+					Pattern: name,
+					Check:   Shadowed,
+					Message: fmt.Sprintf("definition from set %d overwrites an earlier one", i),
+					Offset:  -1,
+				})
+			}
+			registry[name] = definition
+		}
+	}
+
+	for name, definition := range registry {
+		findings = append(findings, lintDefinition(name, definition, registry)...)
+	}
+
+	if len(opts.EntryPoints) > 0 {
+		findings = append(findings, unused(registry, opts.EntryPoints)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Pattern != findings[j].Pattern {
+			return findings[i].Pattern < findings[j].Pattern
+		}
+		if findings[i].Offset != findings[j].Offset {
+			return findings[i].Offset < findings[j].Offset
+		}
+		return findings[i].Check < findings[j].Check
+	})
+
+	return findings
+}
+
+func lintDefinition(name, definition string, registry map[string]string) []Finding {
+	var findings []Finding
+	report := func(check Check, offset int, format string, args ...interface{}) {
+		findings = append(findings, Finding{Pattern: name, Check: check, Message: fmt.Sprintf(format, args...), Offset: offset})
+	}
+
+	expression, err := ast.Parse(definition)
+	if err != nil {
+		var syntaxErr *ast.Error
+		if errors.As(err, &syntaxErr) {
+			report(Invalid, syntaxErr.Offset, "%s", syntaxErr.Message)
+		} else {
+			report(Invalid, -1, "%v", err)
+		}
+		return findings
+	}
+
+	for _, node := range expression.Children {
+		if _, ok := node.(*ast.Alternation); ok {
+			report(TopLevelAlternation, node.Pos(), "alternation outside of a group applies to the whole definition, wrap it in (?:...)")
+		}
+	}
+
+	var unknown bool
+	ast.Inspect(expression, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.Reference:
+			if _, found := registry[n.Syntax]; !found {
+				unknown = true
+				report(UnknownReference, n.Pos(), "pattern %q is not defined", n.Syntax)
+			}
+			if n.Syntax == "GREEDYDATA" && followedByExpression(definition[n.End():]) {
+				report(GreedyInMiddle, n.Pos(), "GREEDYDATA is followed by further expressions and consumes as much as possible")
+			}
+
+		case *ast.CharClass:
+			for _, r := range suspiciousRanges(n.Value) {
+				report(CharClassRange, n.Pos()+r.offset, "range %q matches unexpected characters, escape '-' to match it literally", r.text)
+			}
+
+		case *ast.Literal:
+			lintLiteral(n, definition, report)
+		}
+		return true
+	})
+
+	if unknown {
+		return findings
+	}
+
+	g := grok.NewWithoutDefaultPatterns()
+	if err := g.AddPatterns(registry); err != nil {
+		report(Invalid, -1, "%v", err)
+		return findings
+	}
+	if err := g.Compile(fmt.Sprintf("^(?:%%{%s})$", name), true); err != nil {
+		report(Invalid, -1, "%v", err)
+		return findings
+	}
+	if g.MatchString("") {
+		report(EmptyMatch, -1, "definition matches an empty string")
+	}
+
+	return findings
+}
+
+func lintLiteral(literal *ast.Literal, definition string, report func(check Check, offset int, format string, args ...interface{})) {
+	value := literal.Value
+	for i := 0; i < len(value); i++ {
+		offset := literal.Pos() + i
+
+		if strings.HasPrefix(value[i:], "%{") {
+			malformed := malformedReference(value[i:])
+			report(MalformedReference, offset, "%q is not a valid reference and is matched literally", malformed)
+			i += len(malformed) - 1
+			continue
+		}
+
+		switch value[i] {
+		case '\\':
+			i++
+		case '.':
+			if i+1 < len(value) && strings.ContainsRune("*+?{", rune(value[i+1])) {
+				if value[i+1] == '*' && !strings.HasPrefix(value[i+2:], "?") && followedByExpression(definition[offset+2:]) {
+					report(GreedyInMiddle, offset, ".* is followed by further expressions and consumes as much as possible")
+				}
+				continue
+			}
+			if strings.HasSuffix(definition[:offset], `\\`) {
+				// escaped backslash followed by any character, e.g escape sequence in quoted string
+				continue
+			}
+			report(UnescapedDot, offset, "'.' matches any character, use '\\.' to match a dot")
+		}
+	}
+}
+
+// malformedReference returns text of a malformed reference up to closing brace.
+func malformedReference(rest string) string {
+	if end := strings.IndexByte(rest, '}'); end >= 0 {
+		return rest[:end+1]
+	}
+	return rest
+}
+
+// followedByExpression reports whether rest contains anything matching input,
+// closing groups, optional quantifiers and end anchors are ignored.
+func followedByExpression(rest string) bool {
+	return strings.TrimLeft(rest, ")?*$") != ""
+}
+
+type classRange struct {
+	text   string
+	offset int
+}
+
+// suspiciousRanges returns ranges of a character class which are not digits, lower or upper case letters
+// and do not cover the whole printable ASCII.
+func suspiciousRanges(class string) []classRange {
+	var ranges []classRange
+	body := strings.TrimPrefix(class[1:len(class)-1], "^")
+	offset := len(class) - 1 - len(body)
+
+	for i := 0; i+2 < len(body); i++ {
+		if body[i] == '\\' {
+			i++
+			continue
+		}
+		if body[i+1] != '-' || body[i+2] == '\\' {
+			continue
+		}
+
+		from, to := body[i], body[i+2]
+		if !sameClass(from, to) && !(from <= '!' && to == '~') {
+			ranges = append(ranges, classRange{text: body[i : i+3], offset: offset + i})
+		}
+		i += 2
+	}
+
+	return ranges
+}
+
+func sameClass(from, to byte) bool {
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isLower := func(c byte) bool { return c >= 'a' && c <= 'z' }
+	isUpper := func(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+	return (isDigit(from) && isDigit(to)) || (isLower(from) && isLower(to)) || (isUpper(from) && isUpper(to))
+}
+
+func unused(registry map[string]string, entryPoints []string) []Finding {
+	reachable := make(map[string]bool)
+	queue := append([]string(nil), entryPoints...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if reachable[name] {
+			continue
+		}
+		reachable[name] = true
+
+		expression, err := ast.Parse(registry[name])
+		if err != nil {
+			continue
+		}
+		for _, reference := range ast.References(expression) {
+			queue = append(queue, reference.Syntax)
+		}
+	}
+
+	var findings []Finding
+	for name := range registry {
+		if !reachable[name] {
+			findings = append(findings, Finding{
+				Pattern: name,
+				Check:   Unused,
+				Message: "definition is not used by any entry point",
+				Offset:  -1,
+			})
+		}
+	}
+	return findings
+}
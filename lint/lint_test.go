@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok/lint"
+	"github.com/elastic/go-grok/patterns"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Patterns map[string]string
+		Expected []lint.Finding
+	}{
+		{
+			"clean definition",
+			map[string]string{"NUM": `[0-9]+`, "PAIR": `%{NUM}\.%{NUM}`},
+			nil,
+		},
+		{
+			"unescaped dot",
+			map[string]string{"NUM": `[0-9]+`, "PAIR": `<%{NUM}.%{NUM}>`},
+			[]lint.Finding{{Pattern: "PAIR", Check: lint.UnescapedDot, Offset: 7}},
+		},
+		{
+			"quantified and escaped dots are fine",
+			map[string]string{"QUOTED": `"(?:\\.|[^"])+"`, "ANY": `a.+b`, "LITERAL": `a\.b`},
+			nil,
+		},
+		{
+			"character class range",
+			map[string]string{"LOCAL": `[a-zA-Z0-9_.+-=:]+`},
+			[]lint.Finding{{Pattern: "LOCAL", Check: lint.CharClassRange, Offset: 12}},
+		},
+		{
+			"character class with literal dash",
+			map[string]string{"LOCAL": `[a-zA-Z0-9_.+=:-]+`, "PRINTABLE": `[!-~]+`, "ESCAPED": `[+\-=]`},
+			nil,
+		},
+		{
+			"top level alternation",
+			map[string]string{"A": `a+`, "B": `b+`, "AB": `%{A}|%{B}`, "GROUPED": `(?:%{A}|%{B})`},
+			[]lint.Finding{{Pattern: "AB", Check: lint.TopLevelAlternation, Offset: 4}},
+		},
+		{
+			"greedy data in the middle",
+			map[string]string{"GREEDYDATA": `.+`, "MIDDLE": `%{GREEDYDATA:a}: x`, "END": `x: (%{GREEDYDATA:a})?`, "DOTSTAR": `a.*b`, "LAZY": `a.*?b`},
+			[]lint.Finding{
+				{Pattern: "DOTSTAR", Check: lint.GreedyInMiddle, Offset: 1},
+				{Pattern: "MIDDLE", Check: lint.GreedyInMiddle, Offset: 0},
+			},
+		},
+		{
+			"empty match",
+			map[string]string{"SPACE": `\s*`},
+			[]lint.Finding{{Pattern: "SPACE", Check: lint.EmptyMatch, Offset: -1}},
+		},
+		{
+			"unknown reference",
+			map[string]string{"A": `%{MISSING:a}`},
+			[]lint.Finding{{Pattern: "A", Check: lint.UnknownReference, Offset: 0}},
+		},
+		{
+			"malformed reference",
+			map[string]string{"WORD": `\w+`, "A": `%{WORD:user.name?} x`},
+			[]lint.Finding{{Pattern: "A", Check: lint.MalformedReference, Offset: 0}},
+		},
+		{
+			"invalid regex",
+			map[string]string{"A": `(a`},
+			[]lint.Finding{{Pattern: "A", Check: lint.Invalid, Offset: 2}},
+		},
+		{
+			"invalid repetition",
+			map[string]string{"A": `a**`},
+			[]lint.Finding{{Pattern: "A", Check: lint.Invalid, Offset: -1}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			findings := lint.Run(lint.Options{}, tt.Patterns)
+			require.Equal(t, tt.Expected, withoutMessages(findings))
+		})
+	}
+}
+
+func TestRun_ShadowedAndUnused(t *testing.T) {
+	base := map[string]string{"A": `a`, "B": `b`, "C": `c`}
+	overrides := map[string]string{"A": `aa`, "B": `b`, "D": `%{A}`}
+
+	findings := lint.Run(lint.Options{EntryPoints: []string{"D"}}, base, overrides)
+	require.Equal(t, []lint.Finding{
+		{Pattern: "A", Check: lint.Shadowed, Offset: -1},
+		{Pattern: "B", Check: lint.Unused, Offset: -1},
+		{Pattern: "C", Check: lint.Unused, Offset: -1},
+	}, withoutMessages(findings))
+}
+
+func TestRun_BundledPatterns(t *testing.T) {
+	findings := lint.Run(lint.Options{}, patterns.ECSv1.All()...)
+
+	expected := []lint.Finding{
+		{Pattern: "DATE", Check: lint.TopLevelAlternation, Offset: 10},
+		{Pattern: "SYSLOGFACILITY", Check: lint.UnescapedDot, Offset: 13},
+		{Pattern: "EMAILLOCALPART", Check: lint.CharClassRange, Offset: 20},
+		{Pattern: "CISCOFW302013_302014_302015_302016", Check: lint.MalformedReference, Offset: 316},
+	}
+	for _, e := range expected {
+		require.Contains(t, withoutMessages(findings), e)
+	}
+}
+
+func withoutMessages(findings []lint.Finding) []lint.Finding {
+	var res []lint.Finding
+	for _, f := range findings {
+		f.Message = ""
+		res = append(res, f)
+	}
+	return res
+}
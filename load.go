@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pattern file line: NAME followed by whitespace and definition
+var patternLinePattern = regexp.MustCompile(`^(\w+)\s+(.*)$`)
+
+// ReadPatterns reads pattern definitions in the format used by Logstash pattern files:
+// one definition per line, name separated from the definition by whitespace,
+// empty lines and lines starting with '#' are ignored.
+func ReadPatterns(r io.Reader) (map[string]string, error) {
+	definitions := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m := patternLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected pattern name followed by definition: %w", lineNumber, ErrParseFailure)
+		}
+		definitions[m[1]] = m[2]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+// LoadPatterns reads pattern definitions from a pattern file or from all files of a directory.
+// Files of a directory are read in lexical order, later definitions overwrite earlier ones.
+func LoadPatterns(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	definitions := make(map[string]string)
+	for _, file := range files {
+		fileDefinitions, err := readPatternFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for name, definition := range fileDefinitions {
+			definitions[name] = definition
+		}
+	}
+
+	return definitions, nil
+}
+
+func readPatternFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	definitions, err := ReadPatterns(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return definitions, nil
+}
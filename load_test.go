@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestReadPatterns(t *testing.T) {
+	definitions, err := grok.ReadPatterns(strings.NewReader(`
+# application patterns
+APP_ID [a-z]{3}-\d+
+APP_LINE %{APP_ID:app.id}  %{GREEDYDATA:message}
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"APP_ID":   `[a-z]{3}-\d+`,
+		"APP_LINE": `%{APP_ID:app.id}  %{GREEDYDATA:message}`,
+	}, definitions)
+
+	_, err = grok.ReadPatterns(strings.NewReader("APP_ID\n"))
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+}
+
+func TestLoadPatterns(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("A a\nB b\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b"), []byte("B bb\n"), 0o600))
+
+	definitions, err := grok.LoadPatterns(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"A": "a", "B": "bb"}, definitions)
+
+	definitions, err = grok.LoadPatterns(filepath.Join(dir, "a"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"A": "a", "B": "b"}, definitions)
+
+	_, err = grok.LoadPatterns(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,370 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/elastic/go-grok/internal/ahocorasick"
+)
+
+// Matcher matches text against many expressions at once, e.g. all firewall patterns of a library.
+// Literals required by expressions are searched for in a single pass over text and only expressions
+// with all of their literals present are run. When several expressions match, the one given first wins.
+// Matcher is safe for concurrent use.
+type Matcher struct {
+	expressions []*Grok
+	automaton   *ahocorasick.Automaton
+	// byLiteral lists expressions requiring a literal, indexed the same as literals of automaton
+	byLiteral [][]int
+	// required is the number of distinct literals required by each expression
+	required []int
+	// matches counts lines matched by each expression
+	matches []atomic.Uint64
+	// scratch pools buffers of candidates lookup, see matcherScratch
+	scratch sync.Pool
+
+	// adaptive is set by EnableAdaptiveOrder
+	adaptive *adaptiveOrder
+
+	scoringOnce sync.Once
+	// scoring holds expressions prepared for ParseBestString
+	scoring []scoringExpression
+}
+
+// matcherScratch holds buffers reused by lookups of candidates.
+type matcherScratch struct {
+	// seen marks literals found in text
+	seen []bool
+	// found counts literals found in text per expression
+	found      []int
+	candidates []int
+	// ordered are candidates in adaptive order
+	ordered []int
+}
+
+// adaptiveOrder holds order of expressions learned from matches.
+type adaptiveOrder struct {
+	interval uint64
+	lines    atomic.Uint64
+	// position of each expression in the current order
+	position atomic.Pointer[[]int]
+	// overlapping lists for each expression expressions declared before it which may match the same text
+	overlapping [][]int
+	// recent counts matches since the last reordering
+	recent []atomic.Uint64
+
+	// mu guards reordering and scores
+	mu sync.Mutex
+	// scores are matches decayed by half on every reordering
+	scores []uint64
+}
+
+// ExpressionStats are statistics of an expression of a Matcher.
+type ExpressionStats struct {
+	// Index is the index of expression as given to CompileMatcher.
+	Index int
+	// Matches is the number of lines matched by the expression.
+	Matches uint64
+	// Score is the number of matches with older ones decayed that the adaptive order is based on.
+	Score uint64
+}
+
+// CompileMatcher compiles expressions using patterns known to grok into a matcher.
+// Expressions are prioritized by their order.
+func (grok *Grok) CompileMatcher(expressions []string, namedCapturesOnly bool, opts ...CompileOption) (*Matcher, error) {
+	var cfg compileConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	m := &Matcher{
+		expressions: make([]*Grok, len(expressions)),
+		required:    make([]int, len(expressions)),
+		matches:     make([]atomic.Uint64, len(expressions)),
+	}
+
+	var literals []string
+	indexes := make(map[string]int)
+	for i, expression := range expressions {
+		g := &Grok{
+			patternDefinitions:    grok.patternDefinitions,
+			lookupDefaultPatterns: grok.lookupDefaultPatterns,
+		}
+		if err := g.compile(expression, namedCapturesOnly, cfg); err != nil {
+			return nil, fmt.Errorf("expression %d %q: %w", i, expression, err)
+		}
+		m.expressions[i] = g
+
+		for _, literal := range g.literals {
+			index, found := indexes[literal]
+			if !found {
+				index = len(literals)
+				indexes[literal] = index
+				literals = append(literals, literal)
+				m.byLiteral = append(m.byLiteral, nil)
+			}
+			m.byLiteral[index] = append(m.byLiteral[index], i)
+		}
+		m.required[i] = len(g.literals)
+	}
+	m.automaton = ahocorasick.New(literals)
+	m.scratch.New = func() interface{} {
+		return &matcherScratch{
+			seen:  make([]bool, m.automaton.Len()),
+			found: make([]int, len(m.expressions)),
+		}
+	}
+
+	return m, nil
+}
+
+// Len returns the number of expressions.
+func (m *Matcher) Len() int {
+	return len(m.expressions)
+}
+
+// Expression returns compiled expression at index i, e.g. to get its fields.
+func (m *Matcher) Expression(i int) *Grok {
+	return m.expressions[i]
+}
+
+// Candidates returns indexes of expressions which may match text in order of priority,
+// text contains all literals required by them.
+func (m *Matcher) Candidates(text string) []int {
+	s := m.scratch.Get().(*matcherScratch)
+	defer m.scratch.Put(s)
+
+	var candidates []int
+	return append(candidates, m.candidates(text, s)...)
+}
+
+// candidates returns indexes of expressions which may match text in scratch buffer s.
+func (m *Matcher) candidates(text string, s *matcherScratch) []int {
+	clear(s.found)
+	m.automaton.Find(text, s.seen, func(literal int) {
+		for _, i := range m.byLiteral[literal] {
+			s.found[i]++
+		}
+	})
+
+	s.candidates = s.candidates[:0]
+	for i, count := range s.found {
+		if count == m.required[i] {
+			s.candidates = append(s.candidates, i)
+		}
+	}
+	return s.candidates
+}
+
+// EnableAdaptiveOrder makes the matcher try candidate expressions in order of their recent matches,
+// the order is updated every interval lines and older matches are decayed so that it follows changes of traffic.
+// Declared priority is preserved: a matching expression is returned only after expressions declared before it
+// which may match the same text did not match, expressions not anchored at both ends by ^ and $ may match
+// the same text as any other. Stats returns the learned order.
+// Reordering pays off for expressions anchored at both ends which are not told apart by their literals,
+// e.g. formats differing only in types of fields, when traffic is dominated by expressions declared late.
+// When every expression may match the same text as all expressions declared before it, e.g. when none is
+// anchored, reordering could not save any match and adaptive order is not enabled.
+// It must be called before the matcher is used.
+func (m *Matcher) EnableAdaptiveOrder(interval int) {
+	if interval <= 0 {
+		m.adaptive = nil
+		return
+	}
+
+	progs := make([]*syntax.Prog, len(m.expressions))
+	for i, g := range m.expressions {
+		progs[i] = anchoredProg(g.re.String())
+	}
+
+	a := &adaptiveOrder{
+		interval:    uint64(interval),
+		overlapping: make([][]int, len(m.expressions)),
+		recent:      make([]atomic.Uint64, len(m.expressions)),
+		scores:      make([]uint64, len(m.expressions)),
+	}
+	position := make([]int, len(m.expressions))
+	for j := range m.expressions {
+		position[j] = j
+		for i := 0; i < j; i++ {
+			if mayOverlap(progs[i], progs[j]) {
+				a.overlapping[j] = append(a.overlapping[j], i)
+			}
+		}
+	}
+	a.position.Store(&position)
+
+	for j := range m.expressions {
+		if len(a.overlapping[j]) < j {
+			m.adaptive = a
+			return
+		}
+	}
+	m.adaptive = nil
+}
+
+// Stats returns statistics of expressions in the order they are tried.
+func (m *Matcher) Stats() []ExpressionStats {
+	stats := make([]ExpressionStats, len(m.expressions))
+	for i := range m.expressions {
+		stats[i] = ExpressionStats{Index: i, Matches: m.matches[i].Load()}
+	}
+	if m.adaptive == nil {
+		return stats
+	}
+
+	m.adaptive.mu.Lock()
+	for i := range stats {
+		stats[i].Score = m.adaptive.scores[i]
+	}
+	m.adaptive.mu.Unlock()
+
+	position := *m.adaptive.position.Load()
+	sort.Slice(stats, func(i, j int) bool {
+		return position[stats[i].Index] < position[stats[j].Index]
+	})
+	return stats
+}
+
+// MatchString returns index of the first expression matching text, -1 when none does.
+func (m *Matcher) MatchString(text string) int {
+	return m.first(text)
+}
+
+// ParseString parses text with the first matching expression and returns its index together with
+// values not converted to types according to hints. When no expression matches -1 and nil map are returned.
+func (m *Matcher) ParseString(text string) (int, map[string]string, error) {
+	return parseFirst(m, text, func(g *Grok) func(v, key string) (string, error) {
+		return func(v, _ string) (string, error) {
+			return v, nil
+		}
+	})
+}
+
+// ParseTypedString parses text with the first matching expression and returns its index together with
+// values typed according to type hints. When no expression matches -1 and nil map are returned.
+func (m *Matcher) ParseTypedString(text string) (int, map[string]interface{}, error) {
+	return parseFirst(m, text, func(g *Grok) func(v, key string) (interface{}, error) {
+		return g.convertMatch
+	})
+}
+
+func parseFirst[K any](m *Matcher, text string, conversionFn func(g *Grok) func(v, key string) (K, error)) (int, map[string]K, error) {
+	i := m.first(text)
+	if i < 0 {
+		return -1, nil, nil
+	}
+
+	g := m.expressions[i]
+	captures, err := capturesOf(g.re, g.re.FindStringSubmatch(text), conversionFn(g))
+	if err != nil {
+		return i, nil, err
+	}
+	return i, captures, nil
+}
+
+// first returns index of the first expression matching text, -1 when none does.
+func (m *Matcher) first(text string) int {
+	s := m.scratch.Get().(*matcherScratch)
+	defer m.scratch.Put(s)
+
+	candidates := m.candidates(text, s)
+	if m.adaptive != nil {
+		return m.firstAdaptive(text, candidates, s)
+	}
+
+	for _, i := range candidates {
+		// candidates are often rejected by the regular expression, failing to match is cheaper than failing to submatch
+		if m.expressions[i].re.MatchString(text) {
+			m.matches[i].Add(1)
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Matcher) firstAdaptive(text string, candidates []int, s *matcherScratch) int {
+	a := m.adaptive
+	defer func() {
+		if a.lines.Add(1)%a.interval == 0 {
+			a.reorder()
+		}
+	}()
+
+	position := *a.position.Load()
+
+	// candidates are few, sorting them in place does not allocate
+	s.ordered = append(s.ordered[:0], candidates...)
+	for k := 1; k < len(s.ordered); k++ {
+		for l := k; l > 0 && position[s.ordered[l]] < position[s.ordered[l-1]]; l-- {
+			s.ordered[l], s.ordered[l-1] = s.ordered[l-1], s.ordered[l]
+		}
+	}
+
+	for _, j := range s.ordered {
+		if !m.expressions[j].re.MatchString(text) {
+			continue
+		}
+
+		// declared priority is kept, candidates declared before which may match the same text
+		// and were not tried yet take precedence
+		for _, i := range a.overlapping[j] {
+			if position[i] < position[j] {
+				continue
+			}
+			if k := sort.SearchInts(candidates, i); k < len(candidates) && candidates[k] == i && m.expressions[i].re.MatchString(text) {
+				j = i
+				break
+			}
+		}
+
+		m.matches[j].Add(1)
+		a.recent[j].Add(1)
+		return j
+	}
+	return -1
+}
+
+// reorder updates order of expressions by scores, ties keep declared order.
+func (a *adaptiveOrder) reorder() {
+	if !a.mu.TryLock() {
+		// already being reordered
+		return
+	}
+	defer a.mu.Unlock()
+
+	order := make([]int, len(a.scores))
+	for i := range a.scores {
+		a.scores[i] = a.scores[i]/2 + a.recent[i].Swap(0)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return a.scores[order[i]] > a.scores[order[j]]
+	})
+
+	position := make([]int, len(order))
+	for p, i := range order {
+		position[i] = p
+	}
+	a.position.Store(&position)
+}
@@ -0,0 +1,273 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/generate"
+	"github.com/elastic/go-grok/patterns"
+)
+
+func TestMatcher(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	m, err := g.CompileMatcher([]string{
+		`user=%{WORD:user.name} action=login`,
+		`user=%{WORD:user.name} action=%{WORD:event.action}`,
+		`took %{NUMBER:duration:float}ms`,
+		`%{GREEDYDATA:message}`,
+	}, true)
+	require.NoError(t, err)
+	require.Equal(t, 4, m.Len())
+	require.Equal(t, []grok.Field{{Name: "duration", Type: "float"}}, m.Expression(2).Fields())
+
+	testCases := []struct {
+		Name       string
+		Text       string
+		Candidates []int
+		Index      int
+		Expected   map[string]string
+	}{
+		{"first has priority", "user=alice action=login", []int{0, 1, 3}, 0, map[string]string{"user.name": "alice"}},
+		{"second", "user=alice action=logout", []int{1, 3}, 1, map[string]string{"user.name": "alice", "event.action": "logout"}},
+		{"candidate not matching", "user=alice", []int{3}, 3, map[string]string{"message": "user=alice"}},
+		{"typed", "took 1.5ms", []int{2, 3}, 2, map[string]string{"duration": "1.5"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Candidates, m.Candidates(tc.Text))
+			require.Equal(t, tc.Index, m.MatchString(tc.Text))
+
+			index, values, err := m.ParseString(tc.Text)
+			require.NoError(t, err)
+			require.Equal(t, tc.Index, index)
+			require.Equal(t, tc.Expected, values)
+		})
+	}
+
+	index, typed, err := m.ParseTypedString("took 1.5ms")
+	require.NoError(t, err)
+	require.Equal(t, 2, index)
+	require.Equal(t, map[string]interface{}{"duration": 1.5}, typed)
+}
+
+func TestMatcherNoMatch(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	m, err := g.CompileMatcher([]string{`id=%{INT:id}`, `took %{NUMBER:duration}ms`}, true)
+	require.NoError(t, err)
+
+	require.Empty(t, m.Candidates("nothing here"))
+	require.Equal(t, -1, m.MatchString("nothing here"))
+
+	// literals are present but expressions do not match
+	require.Equal(t, []int{0}, m.Candidates("id=x"))
+	index, values, err := m.ParseString("id=x")
+	require.NoError(t, err)
+	require.Equal(t, -1, index)
+	require.Nil(t, values)
+
+	_, err = g.CompileMatcher([]string{`%{WORD}`, `%{MISSING}`}, true)
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+}
+
+// TestMatcherFirewalls verifies that matcher over all CISCOFW patterns agrees with trying them in order.
+func TestMatcherFirewalls(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	var names []string
+	for name := range patterns.Firewalls {
+		if strings.HasPrefix(name, "CISCOFW") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	expressions := make([]string, len(names))
+	sequential := make([]*grok.Grok, len(names))
+	for i, name := range names {
+		expressions[i] = "%{" + name + "}"
+		sequential[i], err = grok.NewComplete()
+		require.NoError(t, err)
+		require.NoError(t, sequential[i].Compile(expressions[i], true))
+	}
+
+	m, err := g.CompileMatcher(expressions, true)
+	require.NoError(t, err)
+	adaptive, err := g.CompileMatcher(expressions, true)
+	require.NoError(t, err)
+	adaptive.EnableAdaptiveOrder(7)
+
+	r := rand.New(rand.NewSource(1))
+	for _, expression := range expressions {
+		gen, err := generate.New(g, expression, generate.Options{Rand: r})
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			line, err := gen.Generate()
+			require.NoError(t, err)
+
+			expectedIndex, expected := -1, map[string]string(nil)
+			for j, s := range sequential {
+				if s.MatchString(line) {
+					expectedIndex = j
+					expected, err = s.ParseString(line)
+					require.NoError(t, err)
+					break
+				}
+			}
+			require.NotEqual(t, -1, expectedIndex, line)
+
+			index, values, err := m.ParseString(line)
+			require.NoError(t, err)
+			require.Equal(t, expectedIndex, index, line)
+			require.Equal(t, expected, values, line)
+
+			// reordering does not change results
+			index, values, err = adaptive.ParseString(line)
+			require.NoError(t, err)
+			require.Equal(t, expectedIndex, index, line)
+			require.Equal(t, expected, values, line)
+		}
+	}
+}
+
+func TestMatcherAdaptiveOrder(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	m, err := g.CompileMatcher([]string{
+		`^user=%{WORD:user.name} action=login$`,
+		`^took %{INT:duration}ms$`,
+		`^user=%{WORD:user.name} action=%{WORD:event.action}$`,
+		`id=%{INT:id}`,
+	}, true)
+	require.NoError(t, err)
+	m.EnableAdaptiveOrder(10)
+
+	// declared order before any reordering
+	require.Equal(t, []int{0, 1, 2, 3}, statsIndexes(m.Stats()))
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, 2, m.MatchString("user=alice action=logout"))
+	}
+	for i := 0; i < 10; i++ {
+		require.Equal(t, 3, m.MatchString("user=alice action=logout id=1"))
+	}
+
+	// expressions are reordered by decayed matches, older ones count half
+	stats := m.Stats()
+	require.Equal(t, []int{3, 2, 0, 1}, statsIndexes(stats))
+	require.Equal(t, grok.ExpressionStats{Index: 3, Matches: 10, Score: 10}, stats[0])
+	require.Equal(t, grok.ExpressionStats{Index: 2, Matches: 10, Score: 5}, stats[1])
+
+	// overlapping expressions keep priority of declared order even when tried later
+	require.Equal(t, 0, m.MatchString("user=alice action=login"))
+	index, values, err := m.ParseString("user=alice action=login")
+	require.NoError(t, err)
+	require.Equal(t, 0, index)
+	require.Equal(t, map[string]string{"user.name": "alice"}, values)
+	require.Equal(t, uint64(2), m.Stats()[2].Matches)
+
+	// expressions not anchored may match the same line, they are not reordered
+	m, err = g.CompileMatcher([]string{`id=%{INT:id}`, `user=%{WORD:user.name}`}, true)
+	require.NoError(t, err)
+	m.EnableAdaptiveOrder(1)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, 1, m.MatchString("user=alice"))
+	}
+	require.Equal(t, []grok.ExpressionStats{{Index: 0}, {Index: 1, Matches: 5}}, m.Stats())
+	require.Equal(t, 0, m.MatchString("id=1 user=alice"))
+}
+
+func statsIndexes(stats []grok.ExpressionStats) []int {
+	indexes := make([]int, len(stats))
+	for i, s := range stats {
+		indexes[i] = s.Index
+	}
+	return indexes
+}
+
+func TestMatcherParseBestString(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	m, err := g.CompileMatcher([]string{`%{SYSLOGLINE}`, `%{CRONLOG}`, `%{GREEDYDATA:message}`}, true)
+	require.NoError(t, err)
+
+	const line = `Mar 15 12:34:56 host CRON[123]: (root) CMD (run-parts /etc/cron.hourly)`
+
+	// the generic expression is listed first
+	require.Equal(t, 0, m.MatchString(line))
+
+	testCases := []struct {
+		Name     string
+		Policy   grok.ScoringPolicy
+		Expected []int
+	}{
+		{"default", nil, []int{1, 0, 2}},
+		{"most fields", grok.MostFields, []int{1, 0, 2}},
+		{"longest literals", grok.LongestLiterals, []int{1, 0, 2}},
+		{"least wildcard", grok.LeastWildcard, []int{1, 0, 2}},
+		{"ties keep priority", func(grok.MatchDetails) int { return 0 }, []int{0, 1, 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			matches, err := m.ParseBestString(line, tc.Policy, grok.WithRunnersUp(5))
+			require.NoError(t, err)
+
+			indexes := make([]int, len(matches))
+			for i, match := range matches {
+				indexes[i] = match.Index
+			}
+			require.Equal(t, tc.Expected, indexes)
+		})
+	}
+
+	matches, err := m.ParseBestString(line, grok.LeastWildcard)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, grok.MatchDetails{Index: 1, Fields: 5, LiteralLength: 16, WildcardLength: 26}, matches[0].MatchDetails)
+	require.Equal(t, -26, matches[0].Score)
+	require.Equal(t, "run-parts /etc/cron.hourly", matches[0].Values["message"])
+	require.Equal(t, "CMD", matches[0].Values["system.cron.action"])
+
+	matches, err = m.ParseBestString(line, grok.LeastWildcard, grok.WithRunnersUp(1))
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	require.Equal(t, 0, matches[1].Index)
+	require.Equal(t, len("(root) CMD (run-parts /etc/cron.hourly)"), matches[1].WildcardLength)
+
+	m, err = g.CompileMatcher([]string{`id=%{INT:id}`}, true)
+	require.NoError(t, err)
+	matches, err = m.ParseBestString("nothing here", nil)
+	require.NoError(t, err)
+	require.Nil(t, matches)
+}
@@ -0,0 +1,150 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"regexp/syntax"
+	"unicode"
+)
+
+// maxOverlapStates limits states explored by mayOverlap, expressions are assumed to overlap beyond it
+const maxOverlapStates = 1 << 18
+
+// mayOverlap reports whether a single line may be matched by both programs returned by anchoredProg.
+// Any two expressions not anchored at both ends overlap, e.g. on a line made of matches of both,
+// and are given as nil. Anchored expressions are checked by searching the product of their programs
+// for a common match, empty width assertions and case folding are approximated so the result errs
+// on the side of overlap.
+func mayOverlap(progA, progB *syntax.Prog) bool {
+	if progA == nil || progB == nil {
+		return true
+	}
+
+	type state struct{ a, b uint32 }
+	start := state{uint32(progA.Start), uint32(progB.Start)}
+	seen := map[state]bool{start: true}
+	queue := []state{start}
+	push := func(s state) {
+		if !seen[s] {
+			seen[s] = true
+			queue = append(queue, s)
+		}
+	}
+
+	for len(queue) > 0 {
+		if len(seen) > maxOverlapStates {
+			return true
+		}
+		s := queue[0]
+		queue = queue[1:]
+
+		instA, instB := &progA.Inst[s.a], &progB.Inst[s.b]
+
+		// empty transitions are followed on either side first
+		if next, ok := epsilon(instA); ok {
+			for _, n := range next {
+				push(state{n, s.b})
+			}
+			continue
+		}
+		if next, ok := epsilon(instB); ok {
+			for _, n := range next {
+				push(state{s.a, n})
+			}
+			continue
+		}
+
+		if instA.Op == syntax.InstMatch && instB.Op == syntax.InstMatch {
+			return true
+		}
+		if instA.Op == syntax.InstMatch || instB.Op == syntax.InstMatch || instA.Op == syntax.InstFail || instB.Op == syntax.InstFail {
+			continue
+		}
+
+		if rangesIntersect(runeRanges(instA), runeRanges(instB)) {
+			push(state{instA.Out, instB.Out})
+		}
+	}
+
+	return false
+}
+
+// anchoredProg returns program of expression anchored at beginning and end of text, nil otherwise.
+func anchoredProg(expression string) *syntax.Prog {
+	re, err := syntax.Parse(expression, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 ||
+		re.Sub[0].Op != syntax.OpBeginText || re.Sub[len(re.Sub)-1].Op != syntax.OpEndText {
+		return nil
+	}
+
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return nil
+	}
+	return prog
+}
+
+// epsilon returns instructions following inst without consuming input.
+func epsilon(inst *syntax.Inst) ([]uint32, bool) {
+	switch inst.Op {
+	case syntax.InstAlt, syntax.InstAltMatch:
+		return []uint32{inst.Out, inst.Arg}, true
+	case syntax.InstCapture, syntax.InstNop, syntax.InstEmptyWidth:
+		return []uint32{inst.Out}, true
+	}
+	return nil, false
+}
+
+var anyRune = []rune{0, unicode.MaxRune}
+
+// runeRanges returns pairs of ranges of runes consumed by inst.
+func runeRanges(inst *syntax.Inst) []rune {
+	switch inst.Op {
+	case syntax.InstRune1:
+		return []rune{inst.Rune[0], inst.Rune[0]}
+	case syntax.InstRune:
+		if syntax.Flags(inst.Arg)&syntax.FoldCase != 0 {
+			return anyRune
+		}
+		if len(inst.Rune) == 1 {
+			return []rune{inst.Rune[0], inst.Rune[0]}
+		}
+		return inst.Rune
+	case syntax.InstRuneAnyNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}
+	}
+	return anyRune
+}
+
+func rangesIntersect(a, b []rune) bool {
+	for i, j := 0, 0; i+1 < len(a) && j+1 < len(b); {
+		if a[i+1] < b[j] {
+			i += 2
+			continue
+		}
+		if b[j+1] < a[i] {
+			j += 2
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patterns
+
+// Collection groups bundled pattern sets sharing the same field naming flavour.
+// Logstash ships every pattern in two flavours: legacy field names
+// (e.g. clientip, verb, response) and ECS v1 field names
+// (e.g. source.address, http.request.method, http.response.status_code).
+type Collection struct {
+	Default     map[string]string
+	AWS         map[string]string
+	Bind9       map[string]string
+	Bro         map[string]string
+	Exim        map[string]string
+	Firewalls   map[string]string
+	HAProxy     map[string]string
+	Httpd       map[string]string
+	Java        map[string]string
+	Junos       map[string]string
+	Maven       map[string]string
+	MCollective map[string]string
+	MongoDB     map[string]string
+	PostgreSQL  map[string]string
+	Rails       map[string]string
+	Redis       map[string]string
+	Ruby        map[string]string
+	Squid       map[string]string
+	Syslog      map[string]string
+}
+
+// ECSv1 contains pattern sets producing ECS v1 compatible field names.
+var ECSv1 = Collection{
+	Default:     Default,
+	AWS:         AWS,
+	Bind9:       Bind9,
+	Bro:         Bro,
+	Exim:        Exim,
+	Firewalls:   Firewalls,
+	HAProxy:     HAProxy,
+	Httpd:       Httpd,
+	Java:        Java,
+	Junos:       Junos,
+	Maven:       Maven,
+	MCollective: MCollective,
+	MongoDB:     MongoDB,
+	PostgreSQL:  PostgreSQL,
+	Rails:       Rails,
+	Redis:       Redis,
+	Ruby:        Ruby,
+	Squid:       Squid,
+	Syslog:      Syslog,
+}
+
+// Legacy contains pattern sets producing legacy (non-ECS) field names
+// as shipped by Logstash with ECS compatibility disabled.
+var Legacy = Collection{
+	Default:     legacyDefault,
+	AWS:         legacyAWS,
+	Bind9:       legacyBind9,
+	Bro:         legacyBro,
+	Exim:        legacyExim,
+	Firewalls:   legacyFirewalls,
+	HAProxy:     legacyHAProxy,
+	Httpd:       legacyHttpd,
+	Java:        legacyJava,
+	Junos:       legacyJunos,
+	Maven:       legacyMaven,
+	MCollective: legacyMCollective,
+	MongoDB:     legacyMongoDB,
+	PostgreSQL:  legacyPostgreSQL,
+	Rails:       legacyRails,
+	Redis:       legacyRedis,
+	Ruby:        legacyRuby,
+	Squid:       legacySquid,
+	Syslog:      legacySyslog,
+}
+
+// All returns every pattern set of the collection, Default set first.
+// Order matters as sets added later overwrite definitions of sets added earlier.
+func (c Collection) All() []map[string]string {
+	return []map[string]string{
+		c.Default,
+		c.AWS,
+		c.Bind9,
+		c.Bro,
+		c.Exim,
+		c.HAProxy,
+		c.Httpd,
+		c.Firewalls,
+		c.Java,
+		c.Junos,
+		c.Maven,
+		c.MCollective,
+		c.MongoDB,
+		c.PostgreSQL,
+		c.Rails,
+		c.Redis,
+		c.Ruby,
+		c.Squid,
+		c.Syslog,
+	}
+}
+
+// withOverrides returns a copy of base with definitions from overrides applied on top.
+func withOverrides(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for name, definition := range base {
+		merged[name] = definition
+	}
+	for name, definition := range overrides {
+		merged[name] = definition
+	}
+	return merged
+}
@@ -0,0 +1,33 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patterns
+
+var legacyAWS map[string]string = map[string]string{
+	"S3_REQUEST_LINE": `(?:%{WORD:verb} %{NOTSPACE:request}(?: HTTP/%{NUMBER:httpversion})?|%{DATA:rawrequest})`,
+	"S3_ACCESS_LOG":   `%{WORD:owner} %{NOTSPACE:bucket} \[%{HTTPDATE:timestamp}\] (?:-|%{IP:clientip}) (?:-|%{NOTSPACE:requester}) %{NOTSPACE:request_id} %{NOTSPACE:operation} (?:-|%{NOTSPACE:key}) (?:-|"%{S3_REQUEST_LINE}") (?:-|%{INT:response:int}) (?:-|%{NOTSPACE:error_code}) (?:-|%{INT:bytes:int}) (?:-|%{INT:object_size:int}) (?:-|%{INT:request_time_ms:int}) (?:-|%{INT:turnaround_time_ms:int}) "(?:-|%{DATA:referrer})" "(?:-|%{DATA:agent})" (?:-|%{NOTSPACE:version_id})(?: (?:-|%{NOTSPACE:host_id}) (?:-|%{NOTSPACE:signature_version}) (?:-|%{NOTSPACE:cipher_suite}) (?:-|%{NOTSPACE:authentication_type}) (?:-|%{NOTSPACE:host_header}) (?:-|%{NOTSPACE:tls_version}))?`,
+
+	"ELB_URIHOST":      `%{IPORHOST:urihost}(?::%{POSINT:uriport})?`,
+	"ELB_URIPATHQUERY": `%{URIPATH:path}(?:\?%{URIQUERY:params})?`,
+	"ELB_URIPATHPARAM": `%{ELB_URIPATHQUERY}`,
+	"ELB_URI":          `%{URIPROTO:proto}://(?:%{USER}(?::[^@]*)?@)?(?:%{ELB_URIHOST})?(?:%{ELB_URIPATHQUERY})?`,
+	"ELB_REQUEST_LINE": `(?:%{WORD:verb} %{ELB_URI:request}(?: HTTP/%{NUMBER:httpversion})?)`,
+	"ELB_V1_HTTP_LOG":  `%{TIMESTAMP_ISO8601:timestamp} %{NOTSPACE:elb} %{IP:clientip}:%{INT:clientport:int} (?:-|(?:%{IP:backendip}:%{INT:backendport:int})) (?:-1|%{NUMBER:request_processing_time:float}) (?:-1|%{NUMBER:backend_processing_time:float}) (?:-1|%{NUMBER:response_processing_time:float}) %{INT:response:int} (?:-|%{INT:backend_response:int}) %{INT:received_bytes:int} %{INT:bytes:int} "%{ELB_REQUEST_LINE}"(?: "(?:-|%{DATA:agent})" (?:-|%{NOTSPACE:ssl_cipher}) (?:-|%{NOTSPACE:ssl_protocol}))?`,
+	"ELB_ACCESS_LOG":   `%{ELB_V1_HTTP_LOG}`,
+
+	"CLOUDFRONT_ACCESS_LOG": `(?<timestamp>%{YEAR}[-]%{MONTHNUM}[-]%{MONTHDAY}\t%{TIME})\t%{WORD:x_edge_location}\t(?:-|%{INT:sc_bytes:int})\t%{IPORHOST:clientip}\t%{WORD:cs_method}\t%{HOSTNAME:cs_host}\t%{NOTSPACE:cs_uri_stem}\t(?:(?:000)|%{INT:sc_status:int})\t(?:-|%{DATA:referrer})\t%{DATA:agent}\t(?:-|%{DATA:cs_uri_query})\t(?:-|%{DATA:cookies})\t%{WORD:x_edge_result_type}\t%{NOTSPACE:x_edge_request_id}\t%{HOSTNAME:x_host_header}\t%{URIPROTO:cs_protocol}\t(?:-|%{INT:cs_bytes:int})\t%{NUMBER:time_taken:float}\t(?:-|%{IP:x_forwarded_for})\t(?:-|%{DATA:ssl_protocol})\t(?:-|%{NOTSPACE:ssl_cipher})\t%{WORD:x_edge_response_result_type}(?:\t(?:-|HTTP/%{NUMBER:cs_protocol_version})\t(?:-|%{DATA:fle_status})\t(?:-|%{DATA:fle_encrypted_fields})\t%{INT:c_port:int}\t%{NUMBER:time_to_first_byte:float}\t(?:-|%{DATA:x_edge_detailed_result_type})\t(?:-|%{NOTSPACE:sc_content_type})\t(?:-|%{INT:sc_content_len:int})\t(?:-|%{INT:sc_range_start:int})\t(?:-|%{INT:sc_range_end:int}))?`,
+}
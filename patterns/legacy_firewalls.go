@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patterns
+
+var legacyFirewalls map[string]string = map[string]string{
+
+	// NetScreen firewall logs
+	"NETSCREENSESSIONLOG": `%{SYSLOGTIMESTAMP:date} %{IPORHOST:device} %{NOTSPACE:device_name}\: (?P<product>NetScreen) device_id=%{WORD:device_id} .*?(system-(\w+)-(%{NONNEGINT:event_code})\((%{WORD:session_type})\))?\: start_time="%{DATA:start_time}" duration=%{INT:duration} policy_id=%{INT:policy_id} service=%{DATA:service} proto=%{INT:proto} src zone=%{WORD:src_zone} dst zone=%{WORD:dst_zone} action=%{WORD:action} sent=%{INT:sent} rcvd=%{INT:rcvd} src=%{IPORHOST:src_ip} dst=%{IPORHOST:dst_ip}(?: src_port=%{INT:src_port} dst_port=%{INT:dst_port})?(?: src-xlated ip=%{IP:src_xlated_ip} port=%{INT:src_xlated_port} dst-xlated ip=%{IP:dst_xlated_ip} port=%{INT:dst_xlated_port})?(?: session_id=%{INT:session_id} reason=%{GREEDYDATA:reason})?`,
+
+	// == Cisco ASA ==
+	"CISCO_TAGGED_SYSLOG": `^<%{POSINT:syslog_pri}>%{CISCOTIMESTAMP:timestamp}( %{SYSLOGHOST:sysloghost})? ?: %%{CISCOTAG:ciscotag}:`,
+	"CISCOTIMESTAMP":      `%{MONTH} +%{MONTHDAY}(?: %{YEAR})? %{TIME}`,
+	"CISCOTAG":            `[A-Z0-9]+-%{INT}-(?:[A-Z0-9_]+)`,
+
+	// Common Particles
+	"CISCO_ACTION":     `Built|Teardown|Deny|Denied|denied by ACL|requested|permitted|denied|discarded|est-allowed|Dropping|created|deleted`,
+	"CISCO_REASON":     `Duplicate TCP SYN|Failed to locate egress interface|Invalid transport field|No matching connection|DNS Response|DNS Query|(?:%{WORD}\s*)*`,
+	"CISCO_DIRECTION":  `Inbound|inbound|Outbound|outbound`,
+	"CISCO_INTERVAL":   `first hit|%{INT}-second interval`,
+	"CISCO_XLATE_TYPE": `static|dynamic`,
+
+	// helpers
+	"CISCO_HITCOUNT_INTERVAL":     `hit-cnt %{INT:hit_count} (?:first hit|%{INT:interval}-second interval)`,
+	"CISCO_SRC_IP_USER":           `%{NOTSPACE:src_interface}:%{IP:src_ip}(?:\(%{DATA:src_fwuser}\))?`,
+	"CISCO_DST_IP_USER":           `%{NOTSPACE:dst_interface}:%{IP:dst_ip}(?:\(%{DATA:dst_fwuser}\))?`,
+	"CISCO_SRC_HOST_PORT_USER":    `%{NOTSPACE:src_interface}:(?:(?:%{IP:src_ip})|(?:%{HOSTNAME:src_host}))(?:/%{INT:src_port})?(?:\(%{DATA:src_fwuser}\))?`,
+	"CISCO_DST_HOST_PORT_USER":    `%{NOTSPACE:dst_interface}:(?:(?:%{IP:dst_ip})|(?:%{HOSTNAME:dst_host}))(?:/%{INT:dst_port})?(?:\(%{DATA:dst_fwuser}\))?`,
+	"CISCOFW104001":               `\((?:Primary|Secondary)\) Switching to ACTIVE - %{GREEDYDATA:switch_reason}`,
+	"CISCOFW104002":               `\((?:Primary|Secondary)\) Switching to STANDBY - %{GREEDYDATA:switch_reason}`,
+	"CISCOFW104003":               `\((?:Primary|Secondary)\) Switching to FAILED\.`,
+	"CISCOFW104004":               `\((?:Primary|Secondary)\) Switching to OK\.`,
+	"CISCOFW105003":               `\((?:Primary|Secondary)\) Monitoring on [Ii]nterface %{NOTSPACE:interface_name} waiting`,
+	"CISCOFW105004":               `\((?:Primary|Secondary)\) Monitoring on [Ii]nterface %{NOTSPACE:interface_name} normal`,
+	"CISCOFW105005":               `\((?:Primary|Secondary)\) Lost Failover communications with mate on [Ii]nterface %{NOTSPACE:interface_name}`,
+	"CISCOFW105008":               `\((?:Primary|Secondary)\) Testing [Ii]nterface %{NOTSPACE:interface_name}`,
+	"CISCOFW105009":               `\((?:Primary|Secondary)\) Testing on [Ii]nterface %{NOTSPACE:interface_name} (?:Passed|Failed)`,
+	"CISCOFW106001":               `%{CISCO_DIRECTION:direction} %{WORD:protocol} connection %{CISCO_ACTION:action} from %{IP:src_ip}/%{INT:src_port} to %{IP:dst_ip}/%{INT:dst_port} flags %{DATA:tcp_flags} on interface %{NOTSPACE:interface}`,
+	"CISCOFW106006_106007_106010": `%{CISCO_ACTION:action} %{CISCO_DIRECTION:direction} %{WORD:protocol} (?:from|src) %{IP:src_ip}/%{INT:src_port}(?:\(%{DATA:src_fwuser}\))? (?:to|dst) %{IP:dst_ip}/%{INT:dst_port}(?:\(%{DATA:dst_fwuser}\))? (?:(?:on interface %{NOTSPACE:interface})|(?:due to %{CISCO_REASON:reason}))`,
+	"CISCOFW106014":               `%{CISCO_ACTION:action} %{CISCO_DIRECTION:direction} %{WORD:protocol} src %{CISCO_SRC_IP_USER} dst %{CISCO_DST_IP_USER}\s?\(type %{INT:icmp_type}, code %{INT:icmp_code}\)`,
+	"CISCOFW106015":               `%{CISCO_ACTION:action} %{WORD:protocol} \(%{DATA:policy_id}\) from %{IP:src_ip}/%{INT:src_port} to %{IP:dst_ip}/%{INT:dst_port} flags %{DATA:tcp_flags} on interface %{NOTSPACE:interface}`,
+	"CISCOFW106021":               `%{CISCO_ACTION:action} %{WORD:protocol} reverse path check from %{IP:src_ip} to %{IP:dst_ip} on interface %{NOTSPACE:interface}`,
+	"CISCOFW106023":               `%{CISCO_ACTION:action}( protocol)? %{WORD:protocol} src %{DATA:src_interface}:%{DATA:src_ip}(/%{INT:src_port})?(\(%{DATA:src_fwuser}\))? dst %{DATA:dst_interface}:%{DATA:dst_ip}(/%{INT:dst_port})?(\(%{DATA:dst_fwuser}\))?( \(type %{INT:icmp_type}, code %{INT:icmp_code}\))? by access-group "?%{DATA:policy_id}"? \[%{DATA:hashcode1}, %{DATA:hashcode2}\]`,
+	"CISCOFW106100_2_3":           `access-list %{NOTSPACE:policy_id} %{CISCO_ACTION:action} %{WORD:protocol} for user '%{DATA:src_fwuser}' %{DATA:src_interface}\/%{IP:src_ip}\(%{INT:src_port}\) -> %{DATA:dst_interface}\/%{IP:dst_ip}\(%{INT:dst_port}\) %{CISCO_HITCOUNT_INTERVAL} \[%{DATA:hashcode1}\, %{DATA:hashcode2}\]`,
+
+	"CISCOFW106100":                      `access-list %{NOTSPACE:policy_id} %{CISCO_ACTION:action} %{WORD:protocol} %{DATA:src_interface}/%{IP:src_ip}\(%{INT:src_port}\)(?:\(%{DATA:src_fwuser}\))? -> %{DATA:dst_interface}/%{IP:dst_ip}\(%{INT:dst_port}\)(?:\(%{DATA:src_fwuser}\))? hit-cnt %{INT:hit_count} %{CISCO_INTERVAL:interval} \[%{DATA:hashcode1}\, %{DATA:hashcode2}\]`,
+	"CISCOFW304001":                      `%{IP:src_ip}(?:\(%{DATA:src_fwuser}\))? Accessed URL %{IP:dst_ip}:%{GREEDYDATA:dst_url}`,
+	"CISCOFW110002":                      `%{CISCO_REASON:reason} for %{WORD:protocol} from %{DATA:src_interface}:%{IP:src_ip}/%{INT:src_port} to %{IP:dst_ip}/%{INT:dst_port}`,
+	"CISCOFW302010":                      `%{INT:connection_count} in use, %{INT:connection_count_max} most used`,
+	"CISCOFW302013_302014_302015_302016": `%{CISCO_ACTION:action}(?: %{CISCO_DIRECTION:direction})? %{WORD:protocol} connection %{INT:connection_id} for %{NOTSPACE:src_interface}:%{IP:src_ip}/%{INT:src_port}(?: \(%{IP:src_xlated_ip}/%{INT:src_xlated_port}\))?(?:\(%{DATA:src_fwuser}\))? to %{NOTSPACE:dst_interface}:%{IP:dst_ip}/%{INT:dst_port}( \(%{IP:dst_xlated_ip}/%{INT:dst_xlated_port}\))?(?:\(%{DATA:dst_fwuser}\))?( duration %{TIME:duration} bytes %{INT:bytes})?(?: %{CISCO_REASON:reason})?(?: \(%{DATA:user}\))?`,
+	"CISCOFW302020_302021":               `%{CISCO_ACTION:action}(?: %{CISCO_DIRECTION:direction})? %{WORD:protocol} connection for faddr %{IP:dst_ip}/%{INT:icmp_seq_num}(?:\(%{DATA:fwuser}\))? gaddr %{IP:src_xlated_ip}/%{INT:icmp_code_xlated} laddr %{IP:src_ip}/%{INT:icmp_code}(?: \(%{DATA:user}\))?`,
+	"CISCOFW305011":                      `%{CISCO_ACTION:action} %{CISCO_XLATE_TYPE:xlate_type} %{WORD:protocol} translation from %{DATA:src_interface}:%{IP:src_ip}(/%{INT:src_port})?(?:\(%{DATA:src_fwuser}\))? to %{DATA:src_xlated_interface}:%{IP:src_xlated_ip}/%{INT:src_xlated_port}`,
+	"CISCOFW313001_313004_313008":        `%{CISCO_ACTION:action} %{WORD:protocol} type=%{INT:icmp_type}, code=%{INT:icmp_code} from %{IP:src_ip} on interface %{NOTSPACE:interface}(?: to %{IP:dst_ip})?`,
+	"CISCOFW313005":                      `%{CISCO_REASON:reason} for %{WORD:protocol} error message: %{WORD:err_protocol} src %{CISCO_SRC_IP_USER} dst %{CISCO_DST_IP_USER} \(type %{INT:err_icmp_type}, code %{INT:err_icmp_code}\) on %{NOTSPACE:interface} interface\.\s+Original IP payload: %{WORD:orig_protocol} src %{IP:orig_src_ip}/%{INT:orig_src_port}(?:\(%{DATA:orig_src_fwuser}\))? dst %{IP:orig_dst_ip}/%{INT:orig_dst_port}(?:\(%{DATA:orig_dst_fwuser}\))?`,
+	"CISCOFW321001":                      `Resource '%{DATA:resource_name}' limit of %{POSINT:resource_limit} reached for system`,
+	"CISCOFW402117":                      `%{WORD:protocol}: Received a non-IPSec packet \(protocol=\s?%{WORD:orig_protocol}\) from %{IP:src_ip} to %{IP:dst_ip}\.?`,
+	"CISCOFW402119":                      `%{WORD:protocol}: Received an %{WORD:orig_protocol} packet \(SPI=\s?%{DATA:spi}, sequence number=\s?%{DATA:seq_num}\) from %{IP:src_ip} \(user=\s?%{DATA:user}\) to %{IP:dst_ip} that failed anti-replay checking\.?`,
+	"CISCOFW419001":                      `%{CISCO_ACTION:action} %{WORD:protocol} packet from %{NOTSPACE:src_interface}:%{IP:src_ip}/%{INT:src_port} to %{NOTSPACE:dst_interface}:%{IP:dst_ip}/%{INT:dst_port}, reason: %{GREEDYDATA:reason}`,
+	"CISCOFW419002":                      `%{CISCO_REASON:reason} from %{DATA:src_interface}:%{IP:src_ip}/%{INT:src_port} to %{DATA:dst_interface}:%{IP:dst_ip}/%{INT:dst_port} with different initial sequence number`,
+	"CISCOFW500004":                      `%{CISCO_REASON:reason} for protocol=%{WORD:protocol}, from %{IP:src_ip}/%{INT:src_port} to %{IP:dst_ip}/%{INT:dst_port}`,
+	"CISCOFW602303_602304":               `%{WORD:protocol}: An %{CISCO_DIRECTION:direction} %{DATA:tunnel_type} SA \(SPI=%{DATA:spi}\) between %{IP:src_ip} and %{IP:dst_ip} \(user=%{DATA:user}\) has been %{CISCO_ACTION:action}`,
+	"CISCOFW710001_710002_710003_710005_710006": `%{WORD:protocol} (?:request|access) %{CISCO_ACTION:action} from %{IP:src_ip}/%{INT:src_port} to %{DATA:dst_interface}:%{IP:dst_ip}/%{INT:dst_port}`,
+	"CISCOFW713172": `Group = %{DATA:group}, IP = %{IP:src_ip}, Automatic NAT Detection Status:\s+Remote end\s*%{DATA:is_remote_natted}\s*behind a NAT device\s+This\s+end\s*%{DATA:is_local_natted}\s*behind a NAT device`,
+	"CISCOFW733100": `\[\s*%{DATA:drop_type}\s*\] drop %{DATA:drop_rate_id} exceeded\. Current burst rate is %{INT:drop_rate_current_burst} per second, max configured rate is %{INT:drop_rate_max_burst}; Current average rate is %{INT:drop_rate_current_avg} per second, max configured rate is %{INT:drop_rate_max_avg}; Cumulative total count is %{INT:drop_total_count}`,
+
+	"IPTABLES_TCP_FLAGS": `(CWR |ECE |URG |ACK |PSH |RST |SYN |FIN )*`,
+	"IPTABLES_TCP_PART":  `(?:SEQ=%{INT:seq}\s+)?(?:ACK=%{INT:ack}\s+)?WINDOW=%{INT:window}\s+RES=0x%{BASE16NUM:res}\s+%{IPTABLES_TCP_FLAGS:tcp_flags}`,
+
+	"IPTABLES4_FRAG": `((\s)?(CE|DF|MF))*`,
+	"IPTABLES4_PART": `SRC=%{IPV4:src_ip}\s+DST=%{IPV4:dst_ip}\s+LEN=(?:%{INT:length})?\s+TOS=(?:0|0x%{BASE16NUM:tos})?\s+PREC=(?:0x%{BASE16NUM:precedence})?\s+TTL=(?:%{INT:ttl})?\s+ID=(?:%{INT:id})?\s+(?:%{IPTABLES4_FRAG:fragment_flags})?(?:\s+FRAG: %{INT:fragment_offset})?`,
+	"IPTABLES6_PART": `SRC=%{IPV6:src_ip}\s+DST=%{IPV6:dst_ip}\s+LEN=(?:%{INT:length})?\s+TC=(?:0|0x%{BASE16NUM:tos})?\s+HOPLIMIT=(?:%{INT:ttl})?\s+FLOWLBL=(?:%{INT:flow_label})?`,
+
+	"IPTABLES": `IN=(?:%{NOTSPACE:in_device})?\s+OUT=(?:%{NOTSPACE:out_device})?\s+(?:MAC=(?:%{COMMONMAC:dst_mac})?(?::%{COMMONMAC:src_mac})?(?::A-Fa-f0-9{2}:A-Fa-f0-9{2})?\s+)?(:?%{IPTABLES4_PART}|%{IPTABLES6_PART}).*?PROTO=(?:%{WORD:proto})?\s+SPT=(?:%{INT:src_port})?\s+DPT=(?:%{INT:dst_port})?\s+(?:%{IPTABLES_TCP_PART})?`,
+
+	// Shorewall firewall logs
+	"SHOREWALL": `(?:%{SYSLOGTIMESTAMP:timestamp}) (?:%{WORD:nf_host}) .*Shorewall:(?:%{WORD:nf_action1})?:(?:%{WORD:nf_action2})?.*%{IPTABLES}`,
+
+	// == SuSE Firewall 2 ==
+	"SFW2_LOG_PREFIX": `SFW2\-INext\-%{NOTSPACE:nf_action}`,
+	"SFW2":            `((?:%{SYSLOGTIMESTAMP:timestamp})|(?:%{TIMESTAMP_ISO8601:timestamp}))\s*%{HOSTNAME:nf_host}.*?%{SFW2_LOG_PREFIX:nf_prefix}\s*%{IPTABLES}`,
+}
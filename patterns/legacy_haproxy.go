@@ -0,0 +1,30 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patterns
+
+var legacyHAProxy map[string]string = map[string]string{
+	"HAPROXYTIME":                    `\b%{HOUR:haproxy_hour}:%{MINUTE:haproxy_minute}(:%{SECOND:haproxy_second})?\b`,
+	"HAPROXYDATE":                    `%{MONTHDAY:haproxy_monthday}/%{MONTH:haproxy_month}/%{YEAR:haproxy_year}:%{HAPROXYTIME:haproxy_time}.%{INT:haproxy_milliseconds}`,
+	"HAPROXYCAPTUREDREQUESTHEADERS":  `(?:-|%{DATA:captured_request_headers})`,
+	"HAPROXYCAPTUREDRESPONSEHEADERS": `(?:-|%{DATA:captured_response_headers})`,
+	"HAPROXYURI":                     `(?:%{URIPROTO:http_proto}://)?(?:%{USER:http_user}(?::[^@]*)?@)?(?:%{IPORHOST:http_host}(?::%{POSINT:http_port})?)?(?:%{URIPATH:http_uri}(?:\?%{URIQUERY:http_params})?)?`,
+	"HAPROXYHTTPREQUESTLINE":         `(?:<BADREQ>|(?:%{WORD:http_verb} %{HAPROXYURI:http_request}(?: HTTP/%{NUMBER:http_version})?))`,
+	"HAPROXYHTTPBASE":                `%{IP:client_ip}:%{INT:client_port} \[%{HAPROXYDATE:accept_date}\] %{NOTSPACE:frontend_name} %{NOTSPACE:backend_name}/(?:<NOSRV>|%{NOTSPACE:server_name}) (?:-1|%{INT:time_request})/(?:-1|%{INT:time_queue})/(?:-1|%{INT:time_backend_connect})/(?:-1|%{INT:time_backend_response})/%{NOTSPACE:time_duration} %{INT:http_status_code} %{INT:bytes_read} (?:-|%{DATA:captured_request_cookie}) (?:-|%{DATA:captured_response_cookie}) %{NOTSPACE:termination_state} %{INT:actconn}/%{INT:feconn}/%{INT:beconn}/%{INT:srvconn}/%{INT:retries} %{INT:srv_queue}/%{INT:backend_queue}(?: \{%{HAPROXYCAPTUREDREQUESTHEADERS}\}(?: \{%{HAPROXYCAPTUREDRESPONSEHEADERS}\})?)?(?: "%{HAPROXYHTTPREQUESTLINE}"?)?`,
+	"HAPROXYHTTP":                    `(?:%{SYSLOGTIMESTAMP:syslog_timestamp}|%{TIMESTAMP_ISO8601:timestamp8601}) %{IPORHOST:syslog_server} %{SYSLOGPROG}: %{HAPROXYHTTPBASE}`,
+	"HAPROXYTCP":                     `(?:%{SYSLOGTIMESTAMP:syslog_timestamp}|%{TIMESTAMP_ISO8601:timestamp8601}) %{IPORHOST:syslog_server} %{SYSLOGPROG}: %{IP:client_ip}:%{INT:client_port} \[%{HAPROXYDATE:accept_date}\] %{NOTSPACE:frontend_name} %{NOTSPACE:backend_name}/(?:<NOSRV>|%{NOTSPACE:server_name}) (?:-1|%{INT:time_queue})/(?:-1|%{INT:time_backend_connect})/%{NOTSPACE:time_duration} %{INT:bytes_read} %{NOTSPACE:termination_state} %{INT:actconn}/%{INT:feconn}/%{INT:beconn}/%{INT:srvconn}/%{INT:retries} %{INT:srv_queue}/%{INT:backend_queue}`,
+}
@@ -0,0 +1,29 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patterns
+
+// Logstash uses dashes in legacy Junos field names (e.g. src-ip),
+// these are not valid capture group names in Go so underscores are used instead.
+var legacyJunos map[string]string = map[string]string{
+	"RT_FLOW_TAG":   `(?:RT_FLOW_SESSION_CREATE|RT_FLOW_SESSION_CLOSE|RT_FLOW_SESSION_DENY)`,
+	"RT_FLOW_EVENT": `%{RT_FLOW_TAG}`,
+
+	"RT_FLOW1": `%{RT_FLOW_TAG:event}: %{GREEDYDATA:close_reason}: %{IP:src_ip}/%{INT:src_port}->%{IP:dst_ip}/%{INT:dst_port} %{DATA:service} %{IP:nat_src_ip}/%{INT:nat_src_port}->%{IP:nat_dst_ip}/%{INT:nat_dst_port} (?:(?:None)|(?:%{DATA:src_nat_rule_name})) (?:(?:None)|(?:%{DATA:dst_nat_rule_name})) %{INT:protocol_id} %{DATA:policy_name} %{DATA:from_zone} %{DATA:to_zone} %{INT:session_id} \d+\(%{INT:sent}\) \d+\(%{INT:received}\) %{INT:elapsed_time} .*`,
+	"RT_FLOW2": `%{RT_FLOW_TAG:event}: session created %{IP:src_ip}/%{INT:src_port}->%{IP:dst_ip}/%{INT:dst_port} %{DATA:service} %{IP:nat_src_ip}/%{INT:nat_src_port}->%{IP:nat_dst_ip}/%{INT:nat_dst_port} (?:(?:None)|(?:%{DATA:src_nat_rule_name})) (?:(?:None)|(?:%{DATA:dst_nat_rule_name})) %{INT:protocol_id} %{DATA:policy_name} %{DATA:from_zone} %{DATA:to_zone} %{INT:session_id} .*`,
+	"RT_FLOW3": `%{RT_FLOW_TAG:event}: session denied %{IP:src_ip}/%{INT:src_port}->%{IP:dst_ip}/%{INT:dst_port} %{DATA:service} %{INT:protocol_id}\(\d\) %{DATA:policy_name} %{DATA:from_zone} %{DATA:to_zone} (.*)?`,
+}
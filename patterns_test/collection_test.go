@@ -0,0 +1,253 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patterns_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/elastic/go-grok"
+	"github.com/elastic/go-grok/patterns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollections_SameDefinitionNames(t *testing.T) {
+	legacySets := patterns.Legacy.All()
+	ecsSets := patterns.ECSv1.All()
+	require.Equal(t, len(ecsSets), len(legacySets))
+
+	for i := range ecsSets {
+		require.Equal(t, sortedNames(ecsSets[i]), sortedNames(legacySets[i]), "set %d differs", i)
+	}
+}
+
+func TestCollections_Compile(t *testing.T) {
+	collections := map[string]patterns.Collection{
+		"legacy": patterns.Legacy,
+		"ecs_v1": patterns.ECSv1,
+	}
+
+	for collectionName, collection := range collections {
+		for _, set := range collection.All() {
+			for name := range set {
+				t.Run(fmt.Sprintf("%s-%s", collectionName, name), func(t *testing.T) {
+					g, err := grok.NewCompleteWithCollection(collection)
+					require.NoError(t, err)
+					require.NoError(t, g.Compile(fmt.Sprintf("%%{%s}", name), true))
+				})
+			}
+		}
+	}
+}
+
+func TestParseWithCollections(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Pattern         string
+		Text            string
+		ExpectedLegacy  map[string]string
+		ExpectedECSv1   map[string]string
+		ExpectedMissing []string
+	}{
+		{
+			"HTTPD_COMBINEDLOG",
+			`%{HTTPD_COMBINEDLOG}`,
+			"127.0.0.1 user username [26/Jun/2024:12:34:56 -0700] \"GET /index.html HTTP/1.1\" 200 1234 \"referrer\" \"Mozilla/5.0\"",
+			map[string]string{
+				"clientip":    "127.0.0.1",
+				"ident":       "user",
+				"auth":        "username",
+				"timestamp":   "26/Jun/2024:12:34:56 -0700",
+				"verb":        "GET",
+				"request":     "/index.html",
+				"httpversion": "1.1",
+				"response":    "200",
+				"bytes":       "1234",
+				"referrer":    "referrer",
+				"agent":       "Mozilla/5.0",
+			},
+			map[string]string{
+				"source.address":              "127.0.0.1",
+				"apache.access.user.identity": "user",
+				"user.name":                   "username",
+				"timestamp":                   "26/Jun/2024:12:34:56 -0700",
+				"http.request.method":         "GET",
+				"url.original":                "/index.html",
+				"http.version":                "1.1",
+				"http.response.status_code":   "200",
+				"http.response.body.size":     "1234",
+				"http.request.referrer":       "referrer",
+				"user_agent.original":         "Mozilla/5.0",
+			},
+			[]string{"clientip", "source.address"},
+		},
+		{
+			"SYSLOGBASE",
+			`%{SYSLOGBASE}`,
+			"Jun 26 12:34:56 myhost sshd[1234]:",
+			map[string]string{
+				"timestamp": "Jun 26 12:34:56",
+				"logsource": "myhost",
+				"program":   "sshd",
+				"pid":       "1234",
+			},
+			map[string]string{
+				"timestamp": "Jun 26 12:34:56",
+				"host.name": "myhost",
+			},
+			[]string{"logsource", "host.name"},
+		},
+		{
+			"CISCOFW106001",
+			`%{CISCOFW106001}`,
+			"Inbound TCP connection denied from 192.168.1.1/12345 to 10.0.0.1/80 flags SYN on interface outside",
+			map[string]string{
+				"direction": "Inbound",
+				"protocol":  "TCP",
+				"action":    "denied",
+				"src_ip":    "192.168.1.1",
+				"src_port":  "12345",
+				"dst_ip":    "10.0.0.1",
+				"dst_port":  "80",
+				"tcp_flags": "SYN",
+				"interface": "outside",
+			},
+			map[string]string{
+				"cisco.asa.network.direction":    "Inbound",
+				"cisco.asa.network.transport":    "TCP",
+				"cisco.asa.outcome":              "denied",
+				"source.address":                 "192.168.1.1",
+				"source.port":                    "12345",
+				"destination.address":            "10.0.0.1",
+				"destination.port":               "80",
+				"cisco.asa.tcp_flags":            "SYN",
+				"observer.egress.interface.name": "outside",
+			},
+			[]string{"src_ip", "source.address"},
+		},
+		{
+			"REDISMONLOG",
+			`%{REDISMONLOG}`,
+			`1719404096.123456 [0 127.0.0.1:6379] "SET" "key" "value"`,
+			map[string]string{
+				"timestamp": "1719404096.123456",
+				"database":  "0",
+				"client":    "127.0.0.1",
+				"port":      "6379",
+				"command":   "SET",
+				"params":    `"key" "value"`,
+			},
+			map[string]string{
+				"timestamp":          "1719404096.123456",
+				"redis.database.id":  "0",
+				"client.address":     "127.0.0.1",
+				"client.port":        "6379",
+				"redis.command.name": "SET",
+				"redis.command.args": `"key" "value"`,
+			},
+			[]string{"client", "client.address"},
+		},
+		{
+			"RUBY_LOGGER",
+			`%{RUBY_LOGGER}`,
+			"I, [2024-06-26T12:34:56.789012 #12345]  INFO -- main: Application started",
+			map[string]string{
+				"timestamp": "2024-06-26T12:34:56.789012",
+				"pid":       "12345",
+				"loglevel":  "INFO",
+				"progname":  "main",
+				"message":   "Application started",
+			},
+			map[string]string{
+				"timestamp":       "2024-06-26T12:34:56.789012",
+				"process.pid":     "12345",
+				"log.level":       "INFO",
+				"process.command": "main",
+				"message":         "Application started",
+			},
+			[]string{"pid", "process.pid"},
+		},
+		{
+			"SYSLOG5424LINE",
+			`%{SYSLOG5424LINE}`,
+			"<34>1 2024-06-26T12:34:56.789Z myhost myapp 1234 ID47 [exampleSDID@32473 iut=\"3\"] An application event log entry",
+			map[string]string{
+				"syslog5424_pri":   "34",
+				"syslog5424_ver":   "1",
+				"syslog5424_ts":    "2024-06-26T12:34:56.789Z",
+				"syslog5424_host":  "myhost",
+				"syslog5424_app":   "myapp",
+				"syslog5424_proc":  "1234",
+				"syslog5424_msgid": "ID47",
+				"syslog5424_sd":    "[exampleSDID@32473 iut=\"3\"]",
+				"syslog5424_msg":   "An application event log entry",
+			},
+			map[string]string{
+				"log.syslog.priority":           "34",
+				"system.syslog.version":         "1",
+				"timestamp":                     "2024-06-26T12:34:56.789Z",
+				"host.name":                     "myhost",
+				"process.command":               "myapp",
+				"process.pid":                   "1234",
+				"event.code":                    "ID47",
+				"system.syslog.structured_data": "[exampleSDID@32473 iut=\"3\"]",
+				"message":                       "An application event log entry",
+			},
+			[]string{"syslog5424_host", "host.name"},
+		},
+	}
+
+	for _, tt := range testCases {
+		for collectionName, expected := range map[string]map[string]string{"legacy": tt.ExpectedLegacy, "ecs_v1": tt.ExpectedECSv1} {
+			t.Run(fmt.Sprintf("%s-%s", tt.Name, collectionName), func(t *testing.T) {
+				collection := patterns.ECSv1
+				unexpected := tt.ExpectedMissing[0]
+				if collectionName == "legacy" {
+					collection = patterns.Legacy
+					unexpected = tt.ExpectedMissing[1]
+				}
+
+				g, err := grok.NewCompleteWithCollection(collection)
+				require.NoError(t, err)
+				require.NoError(t, g.Compile(tt.Pattern, true))
+
+				res, err := g.ParseString(tt.Text)
+				require.NoError(t, err)
+
+				for k, v := range expected {
+					val, found := res[k]
+					require.True(t, found, "Key %q not found", k)
+					require.Equalf(t, v, val, "Values not equal for key. Expected %q, have %q", k, v, val)
+				}
+
+				_, found := res[unexpected]
+				require.False(t, found, "Key %q should not be present", unexpected)
+			})
+		}
+	}
+}
+
+func sortedNames(set map[string]string) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -720,6 +720,20 @@ func TestParseWithPatterns_Firewalls(t *testing.T) {
 				"cisco.asa.ipsec.spi":         "spi",
 			},
 		},
+		{
+			"CISCOFW733100",
+			"%{CISCOFW733100}",
+			`[ Scanning ] drop rate-1 exceeded. Current burst rate is 10 per second, max configured rate is 20; Current average rate is 5 per second, max configured rate is 8; Cumulative total count is 1234`,
+			map[string]string{
+				"cisco.asa.burst.object":              "Scanning",
+				"cisco.asa.burst.id":                  "rate-1",
+				"cisco.asa.burst.current_rate":        "10",
+				"cisco.asa.burst.configured_rate":     "20",
+				"cisco.asa.burst.avg_rate":            "5",
+				"cisco.asa.burst.configured_avg_rate": "8",
+				"cisco.asa.burst.cumulative_count":    "1234",
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Precompiled is an expression expanded ahead of time, e.g. by package codegen,
+// so that it is used without expanding pattern definitions at runtime.
+type Precompiled struct {
+	// Regex is the expanded regular expression as returned by Expand.
+	Regex string
+	// Fields are captured fields of Regex in order of appearance with their type hints.
+	Fields []Field
+}
+
+// NewPrecompiled creates a grok parser from expression expanded ahead of time.
+// The parser supports the same parsing API as a compiled one but has no pattern definitions,
+// methods working with the source expression, Explain and InferTypes, return ErrNotCompiled.
+// Fields not matching capture groups of Regex result in ErrParseFailure, e.g. when generated code is stale.
+func NewPrecompiled(p Precompiled) (*Grok, error) {
+	re, err := regexp.Compile(p.Regex)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := make(map[string]string)
+	for _, field := range p.Fields {
+		if field.Type != "" {
+			hints[groupName(field.Name)] = field.Type
+		}
+	}
+
+	actual := fields(re, hints)
+	if len(actual) != len(p.Fields) {
+		return nil, fmt.Errorf("expected %d fields, regex captures %d: %w", len(p.Fields), len(actual), ErrParseFailure)
+	}
+	for i, field := range actual {
+		if field.Name != p.Fields[i].Name {
+			return nil, fmt.Errorf("expected field %q, regex captures %q: %w", p.Fields[i].Name, field.Name, ErrParseFailure)
+		}
+	}
+
+	literals, err := requiredLiterals(p.Regex)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Grok{
+		patternDefinitions: make(map[string]string),
+		re:                 re,
+		typeHints:          hints,
+		precompiled:        true,
+	}
+	g.setLiterals(literals)
+	return g, nil
+}
+
+// MustPrecompiled is like NewPrecompiled but panics on error, it is meant for package level variables of generated code.
+func MustPrecompiled(p Precompiled) *Grok {
+	g, err := NewPrecompiled(p)
+	if err != nil {
+		panic("grok: precompiled expression: " + err.Error())
+	}
+	return g
+}
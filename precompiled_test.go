@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestNewPrecompiled(t *testing.T) {
+	g, err := grok.NewPrecompiled(grok.Precompiled{
+		Regex:  `(?P<user___name>\w+) took (?P<duration>\d+)ms`,
+		Fields: []grok.Field{{Name: "user.name"}, {Name: "duration", Type: "int"}},
+	})
+	require.NoError(t, err)
+
+	values, err := g.ParseTypedString("alice took 12ms")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"user.name": "alice", "duration": 12}, values)
+	require.Equal(t, []grok.Field{{Name: "user.name"}, {Name: "duration", Type: "int"}}, g.Fields())
+
+	// source expression is not available
+	_, err = g.Explain("alice took 12s")
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+	_, err = g.InferTypes([]string{"alice took 12ms"})
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+}
+
+func TestNewPrecompiledErrors(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Precompiled grok.Precompiled
+	}{
+		{"invalid regex", grok.Precompiled{Regex: `(?P<a>\w+`, Fields: []grok.Field{{Name: "a"}}}},
+		{"missing field", grok.Precompiled{Regex: `(?P<a>\w+) (?P<b>\w+)`, Fields: []grok.Field{{Name: "a"}}}},
+		{"different field", grok.Precompiled{Regex: `(?P<a>\w+)`, Fields: []grok.Field{{Name: "b"}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := grok.NewPrecompiled(tc.Precompiled)
+			require.Error(t, err)
+			require.Panics(t, func() { grok.MustPrecompiled(tc.Precompiled) })
+		})
+	}
+}
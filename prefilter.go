@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"bytes"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// minLiteralLength excludes short literals like a single space present in almost every line
+	minLiteralLength = 2
+	// maxLiterals limits literals checked before matching, the longest ones are the most selective
+	maxLiterals = 4
+)
+
+// requiredLiterals returns literal substrings present in every text matching expression,
+// the longest first. Text lacking any of them is rejected without running the regular expression.
+func requiredLiterals(expression string) ([]string, error) {
+	re, err := syntax.Parse(expression, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	_, required := literalsOf(re)
+
+	seen := make(map[string]bool)
+	literals := make([]string, 0, len(required))
+	for _, literal := range required {
+		if len(literal) < minLiteralLength || seen[literal] {
+			continue
+		}
+		seen[literal] = true
+		literals = append(literals, literal)
+	}
+	sort.SliceStable(literals, func(i, j int) bool {
+		return len(literals[i]) > len(literals[j])
+	})
+	if len(literals) > maxLiterals {
+		literals = literals[:maxLiterals]
+	}
+
+	return literals, nil
+}
+
+// literalsOf returns exact text matched by re when it matches only a single literal text,
+// and literals required by every match of re otherwise.
+func literalsOf(re *syntax.Regexp) (exact *string, required []string) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		literal := string(re.Rune)
+		// case folded literals and replacement characters matching invalid UTF-8 are not searched for
+		if re.Flags&syntax.FoldCase != 0 || strings.ContainsRune(literal, utf8.RuneError) {
+			return nil, nil
+		}
+		return &literal, nil
+
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		empty := ""
+		return &empty, nil
+
+	case syntax.OpCapture:
+		return literalsOf(re.Sub[0])
+
+	case syntax.OpPlus:
+		return nil, requiredBy(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return nil, requiredBy(re.Sub[0])
+		}
+
+	case syntax.OpConcat:
+		// consecutive exact elements form a single literal, e.g. `" HTTP/` in `"(?: HTTP/)`
+		var run strings.Builder
+		exactConcat := true
+		flush := func() {
+			if run.Len() > 0 {
+				required = append(required, run.String())
+				run.Reset()
+			}
+		}
+		for _, sub := range re.Sub {
+			subExact, subRequired := literalsOf(sub)
+			if subExact != nil {
+				run.WriteString(*subExact)
+				continue
+			}
+			exactConcat = false
+			flush()
+			required = append(required, subRequired...)
+		}
+		if exactConcat {
+			literal := run.String()
+			return &literal, nil
+		}
+		flush()
+		return nil, required
+	}
+
+	// alternations, optional elements and character classes do not require any literal
+	return nil, nil
+}
+
+// requiredBy returns literals required by re regardless of it being exact.
+func requiredBy(re *syntax.Regexp) []string {
+	exact, literals := literalsOf(re)
+	if exact != nil {
+		return []string{*exact}
+	}
+	return literals
+}
+
+// setLiterals sets literals required by compiled expression.
+func (grok *Grok) setLiterals(literals []string) {
+	grok.literals = literals
+	grok.literalBytes = make([][]byte, len(literals))
+	for i, literal := range literals {
+		grok.literalBytes[i] = []byte(literal)
+	}
+}
+
+// mayMatchString reports whether text contains all literals required by compiled expression.
+// Text passing the check is submatched right away, matching it first costs nearly as much as
+// extracting submatches with RE2 and would slow down matching text considerably. Only expressions
+// requiring no literals, which this check does not reject anything for, are matched first when
+// compiled with WithMatchFirst.
+func (grok *Grok) mayMatchString(text string) bool {
+	for _, literal := range grok.literals {
+		if !strings.Contains(text, literal) {
+			return false
+		}
+	}
+	return true
+}
+
+// mayMatch is mayMatchString for text in a form of []byte.
+func (grok *Grok) mayMatch(text []byte) bool {
+	for _, literal := range grok.literalBytes {
+		if !bytes.Contains(text, literal) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name       string
+		Expression string
+		Expected   []string
+	}{
+		{"bundled pattern", "%{CISCOFW106001}", []string{" on interface ", " connection ", " flags ", " from "}},
+		{"literals joined across groups", `id=(?:%{INT:id}) took (?:\b)ms`, []string{" took ms", "id="}},
+		{"repetition", `x(?:ab)+cd`, []string{"ab", "cd"}},
+		{"optional part", `GET(?: HTTP/%{NUMBER})?`, []string{"GET"}},
+		{"alternation", `a+bc|bcd`, []string{}},
+		{"case insensitive", `(?i)error: %{GREEDYDATA}`, []string{}},
+		{"short literal", `%{WORD}:%{WORD}`, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			expansion, err := g.Expand(tc.Expression, true)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, expansion.RequiredLiterals)
+		})
+	}
+}
+
+func TestPrefilter(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name       string
+		Expression string
+		Text       string
+		Expected   map[string]string
+	}{
+		{"match", `id=%{INT:id} took %{INT:took}ms`, "id=1 took 12ms", map[string]string{"id": "1", "took": "12"}},
+		{"missing literal", `id=%{INT:id} took %{INT:took}ms`, "id=1 took 12s", map[string]string{}},
+		{"literals present without match", `id=%{INT:id} took %{INT:took}ms`, "ms took id=", map[string]string{}},
+		{"case insensitive", `(?i)error: %{GREEDYDATA:message}`, "ERROR: disk full", map[string]string{"message": "disk full"}},
+		{"unicode literal", `čas=%{INT:time}`, "čas=12", map[string]string{"time": "12"}},
+		{"no literals", `%{WORD:key}=%{INT:value}`, "a=1", map[string]string{"key": "a", "value": "1"}},
+		{"no literals without match", `%{WORD:key}=%{INT:value}`, "a=b", map[string]string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			for _, matchFirst := range []bool{false, true} {
+				var opts []grok.CompileOption
+				if matchFirst {
+					opts = append(opts, grok.WithMatchFirst())
+				}
+
+				require.NoError(t, g.Compile(tc.Expression, true, opts...))
+
+				require.Equal(t, len(tc.Expected) > 0, g.MatchString(tc.Text))
+				require.Equal(t, len(tc.Expected) > 0, g.Match([]byte(tc.Text)))
+
+				values, err := g.ParseString(tc.Text)
+				require.NoError(t, err)
+				require.Equal(t, tc.Expected, values)
+
+				bytesValues, err := g.Parse([]byte(tc.Text))
+				require.NoError(t, err)
+				require.Len(t, bytesValues, len(tc.Expected))
+
+				typed, err := g.ParseTypedString(tc.Text)
+				require.NoError(t, err)
+				require.Len(t, typed, len(tc.Expected))
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Redaction returns replacement of value captured into field.
+type Redaction func(field, value string) string
+
+// RedactPolicy selects fields to redact, keyed by field name.
+type RedactPolicy map[string]Redaction
+
+// Mask replaces values with fixed mask.
+func Mask(mask string) Redaction {
+	return func(_, _ string) string {
+		return mask
+	}
+}
+
+// KeyedHash replaces values with hex encoded HMAC-SHA256 of field and value,
+// equal values of a field are replaced by equal hashes.
+func KeyedHash(key []byte) Redaction {
+	return func(field, value string) string {
+		return hex.EncodeToString(keyedSum(key, field, value))
+	}
+}
+
+// PreserveFormat replaces ASCII digits with digits and ASCII letters with letters of the same case,
+// other characters are kept, e.g. an email address is replaced by another address of the same shape.
+// Substitutes are derived from key, field and value so equal values are replaced equally.
+// Only the shape of values is preserved, e.g. substitutes of IP addresses may have octets above 255.
+func PreserveFormat(key []byte) Redaction {
+	return func(field, value string) string {
+		stream := keyedSum(key, field, value)
+		next := 0
+		random := func(n byte) byte {
+			if next == len(stream) {
+				sum := sha256.Sum256(stream)
+				stream, next = sum[:], 0
+			}
+			next++
+			return stream[next-1] % n
+		}
+
+		var sb strings.Builder
+		sb.Grow(len(value))
+		for i := 0; i < len(value); i++ {
+			switch c := value[i]; {
+			case c >= '0' && c <= '9':
+				sb.WriteByte('0' + random(10))
+			case c >= 'a' && c <= 'z':
+				sb.WriteByte('a' + random(26))
+			case c >= 'A' && c <= 'Z':
+				sb.WriteByte('A' + random(26))
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return sb.String()
+	}
+}
+
+func keyedSum(key []byte, field, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// Redact replaces values of fields selected by policy within text, the rest of text is kept byte for byte.
+// When both a field and fields nested in it are selected, the enclosing field is redacted as a whole.
+// Text not matching the expression results in ErrParseFailure so that unredacted text is not passed on.
+func (grok *Grok) Redact(text string, policy RedactPolicy) (string, error) {
+	if grok == nil || grok.re == nil {
+		return "", ErrNotCompiled
+	}
+
+	captured := make(map[string]bool)
+	for _, field := range grok.Fields() {
+		captured[field.Name] = true
+	}
+	for name := range policy {
+		if !captured[name] {
+			return "", fmt.Errorf("field %q not captured by expression", name)
+		}
+	}
+
+	spans := grok.Spans(text)
+	if spans == nil {
+		return "", fmt.Errorf("text does not match expression: %w", ErrParseFailure)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, span := range spans {
+		redact, found := policy[span.Field]
+		// spans are ordered by start, enclosing first, nested ones are already replaced
+		if !found || span.Start < last {
+			continue
+		}
+		sb.WriteString(text[last:span.Start])
+		sb.WriteString(redact(span.Field, text[span.Start:span.End]))
+		last = span.End
+	}
+	sb.WriteString(text[last:])
+
+	return sb.String(), nil
+}
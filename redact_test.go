@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestRedact(t *testing.T) {
+	const line = `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`
+
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+	require.NoError(t, g.Compile("%{HTTPD_COMMONLOG}", true))
+
+	key := []byte("secret")
+
+	testCases := []struct {
+		Name     string
+		Policy   grok.RedactPolicy
+		Expected string
+	}{
+		{
+			"mask",
+			grok.RedactPolicy{"user.name": grok.Mask("***"), "source.address": grok.Mask("x.x.x.x")},
+			`x.x.x.x - *** [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+		},
+		{
+			"empty policy",
+			grok.RedactPolicy{},
+			line,
+		},
+		{
+			"nested field",
+			grok.RedactPolicy{"url.original": grok.Mask("/redacted")},
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /redacted HTTP/1.0" 200 2326`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			redacted, err := g.Redact(line, tc.Policy)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, redacted)
+		})
+	}
+
+	t.Run("keyed hash", func(t *testing.T) {
+		redacted, err := g.Redact(line, grok.RedactPolicy{"user.name": grok.KeyedHash(key)})
+		require.NoError(t, err)
+		require.Regexp(t, `^127\.0\.0\.1 - [0-9a-f]{64} \[10/Oct/2000:13:55:36 -0700\] "GET /apache_pb.gif HTTP/1.0" 200 2326$`, redacted)
+
+		again, err := g.Redact(line, grok.RedactPolicy{"user.name": grok.KeyedHash(key)})
+		require.NoError(t, err)
+		require.Equal(t, redacted, again)
+
+		otherKey, err := g.Redact(line, grok.RedactPolicy{"user.name": grok.KeyedHash([]byte("other"))})
+		require.NoError(t, err)
+		require.NotEqual(t, redacted, otherKey)
+	})
+
+	t.Run("format preserving", func(t *testing.T) {
+		policy := grok.RedactPolicy{"source.address": grok.PreserveFormat(key), "user.name": grok.PreserveFormat(key)}
+		redacted, err := g.Redact(line, policy)
+		require.NoError(t, err)
+		require.Len(t, redacted, len(line))
+		require.Regexp(t, `^\d{3}\.\d\.\d\.\d - [a-z]{5} \[10/Oct/2000:13:55:36 -0700\] "GET /apache_pb.gif HTTP/1.0" 200 2326$`, redacted)
+		require.NotEqual(t, line, redacted)
+
+		// redacted line is still parsed by the same expression
+		values, err := g.ParseString(redacted)
+		require.NoError(t, err)
+		require.Equal(t, "GET", values["http.request.method"])
+		require.Regexp(t, `^[a-z]{5}$`, values["user.name"])
+	})
+}
+
+func TestRedactEnclosingField(t *testing.T) {
+	g := grok.New()
+	require.NoError(t, g.AddPattern("ADDRESS", `%{WORD:host}:%{INT:port}`))
+	require.NoError(t, g.AddPattern("WORD", `\w+`))
+	require.NoError(t, g.AddPattern("INT", `\d+`))
+	require.NoError(t, g.Compile(`from %{ADDRESS:address} as %{WORD:user}`, true))
+
+	redacted, err := g.Redact("from db:5432 as admin", grok.RedactPolicy{
+		"address": grok.Mask("[address]"),
+		"port":    grok.Mask("[port]"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "from [address] as admin", redacted)
+}
+
+func TestRedactErrors(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	_, err = g.Redact("text", grok.RedactPolicy{})
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+
+	require.NoError(t, g.Compile("%{HTTPD_COMMONLOG}", true))
+
+	_, err = g.Redact("not a log line", grok.RedactPolicy{"user.name": grok.Mask("***")})
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+
+	_, err = g.Redact(`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 200 1`, grok.RedactPolicy{"email": grok.Mask("***")})
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"strings"
+)
+
+// renamedGroup returns name of capture group with renames applied, false when the field is dropped.
+// renames are keyed by field names as they appear in parse results (using '.' separator).
+func renamedGroup(name string, renames map[string]string) (string, bool) {
+	target, found := renames[fieldName(name)]
+	switch {
+	case !found:
+		return name, true
+	case target == "":
+		return "", false
+	}
+	return groupName(target), true
+}
+
+// checkRenames verifies that renames do not merge fields captured by an expression,
+// renames of fields not captured by the expression are ignored.
+func checkRenames(captured map[string]bool, renames map[string]string) error {
+	sources := make(map[string]string, len(renames))
+	for source, target := range renames {
+		if !captured[source] || target == "" || target == source {
+			continue
+		}
+
+		if captured[target] {
+			if renamedTarget, renamedAway := renames[target]; !renamedAway || renamedTarget == target {
+				return fmt.Errorf("renaming %q to %q: %w", source, target, ErrRenameConflict)
+			}
+		}
+
+		if otherSource, found := sources[target]; found {
+			return fmt.Errorf("renaming both %q and %q to %q: %w", otherSource, source, target, ErrRenameConflict)
+		}
+		sources[target] = source
+	}
+
+	return nil
+}
+
+// fieldName converts capture group name to field name as returned in parse results.
+func fieldName(groupName string) string {
+	return strings.ReplaceAll(groupName, dotSep, ".")
+}
+
+// groupName converts field name to capture group name.
+func groupName(fieldName string) string {
+	return strings.ReplaceAll(fieldName, ".", dotSep)
+}
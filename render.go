@@ -0,0 +1,304 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingField  = fmt.Errorf("field value not provided")
+	ErrRenderFailure = fmt.Errorf("rendering failed")
+)
+
+// RenderOption customizes rendering of a line.
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	defaults map[string]interface{}
+}
+
+// WithDefaults provides values of fields used when not present in rendered fields.
+func WithDefaults(defaults map[string]interface{}) RenderOption {
+	return func(cfg *renderConfig) {
+		cfg.defaults = defaults
+	}
+}
+
+// Render builds a line matching compiled expression with captures filled by values of fields, the reverse of Parse.
+// Values are formatted as text, e.g. 200 as "200", and must match the pattern of their capture.
+// Alternation branches and optional parts are chosen to render as many of the fields as possible,
+// other parts of expression are rendered as the shortest text they match, e.g. a single space for `\s+`.
+// Missing values of fields which are not optional result in ErrMissingField, unless provided by WithDefaults.
+// Rendered line is parsed back and ErrRenderFailure is returned when it does not result in the same values.
+func (grok *Grok) Render(fields map[string]interface{}, opts ...RenderOption) (string, error) {
+	if grok == nil || grok.re == nil {
+		return "", ErrNotCompiled
+	}
+
+	var cfg renderConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	values := make(map[string]string, len(fields)+len(cfg.defaults))
+	for name, value := range cfg.defaults {
+		values[name] = formatValue(value)
+	}
+	for name, value := range fields {
+		values[name] = formatValue(value)
+	}
+
+	captured := make(map[string]bool)
+	for _, field := range grok.Fields() {
+		captured[field.Name] = true
+	}
+	for name := range fields {
+		if !captured[name] {
+			return "", fmt.Errorf("field %q not captured by expression: %w", name, ErrRenderFailure)
+		}
+	}
+
+	tree, err := syntax.Parse(grok.re.String(), syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	r := renderer{values: values, compiled: make(map[*syntax.Regexp]*regexp.Regexp)}
+	rendered, err := r.render(tree)
+	if err != nil {
+		return "", err
+	}
+
+	// rendered values may be split differently by surrounding elements, e.g. greedy quantifiers
+	if !grok.re.MatchString(rendered.text) {
+		return "", fmt.Errorf("rendered line %q does not match: %w", rendered.text, ErrRenderFailure)
+	}
+	parsed, err := grok.ParseString(rendered.text)
+	if err != nil {
+		return "", err
+	}
+	// provided fields are verified even when not rendered, e.g. nested in a field with a value as well
+	verify := make(map[string]bool, len(fields)+len(rendered.used))
+	for name := range fields {
+		verify[name] = true
+	}
+	for name := range rendered.used {
+		verify[name] = true
+	}
+	names := make([]string, 0, len(verify))
+	for name := range verify {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if parsed[name] != values[name] {
+			return "", fmt.Errorf("field %q of rendered line %q parsed as %q instead of %q: %w", name, rendered.text, parsed[name], values[name], ErrRenderFailure)
+		}
+	}
+
+	return rendered.text, nil
+}
+
+type renderer struct {
+	values map[string]string
+	// compiled caches expressions of captures values are checked against
+	compiled map[*syntax.Regexp]*regexp.Regexp
+}
+
+// rendered is text of an element with fields it contains.
+type rendered struct {
+	text string
+	used map[string]bool
+}
+
+func (r *renderer) render(re *syntax.Regexp) (rendered, error) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return rendered{text: string(re.Rune)}, nil
+
+	case syntax.OpCharClass:
+		return rendered{text: string(classRune(re.Rune))}, nil
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return rendered{text: "x"}, nil
+
+	case syntax.OpCapture:
+		if re.Name == "" {
+			return r.render(re.Sub[0])
+		}
+		return r.renderCapture(re)
+
+	case syntax.OpStar, syntax.OpQuest:
+		return r.renderOptional(re.Sub[0]), nil
+
+	case syntax.OpPlus:
+		return r.render(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			if re.Max == 0 {
+				return rendered{}, nil
+			}
+			return r.renderOptional(re.Sub[0]), nil
+		}
+
+		// the same fields are rendered by every repetition
+		sub, err := r.render(re.Sub[0])
+		if err != nil {
+			return rendered{}, err
+		}
+		return rendered{text: strings.Repeat(sub.text, re.Min), used: sub.used}, nil
+
+	case syntax.OpConcat:
+		var sb strings.Builder
+		used := make(map[string]bool)
+		for _, sub := range re.Sub {
+			part, err := r.render(sub)
+			if err != nil {
+				return rendered{}, err
+			}
+			sb.WriteString(part.text)
+			for name := range part.used {
+				used[name] = true
+			}
+		}
+		return rendered{text: sb.String(), used: used}, nil
+
+	case syntax.OpAlternate:
+		return r.renderAlternate(re.Sub)
+	}
+
+	// empty matches, anchors and word boundaries do not render any text
+	return rendered{}, nil
+}
+
+func (r *renderer) renderCapture(re *syntax.Regexp) (rendered, error) {
+	field := fieldName(re.Name)
+	value, found := r.values[field]
+	if !found {
+		sub, err := r.render(re.Sub[0])
+		if err != nil {
+			return rendered{}, err
+		}
+		// fields enclosing provided fields are rendered from them, e.g. address from host and port
+		if len(sub.used) == 0 {
+			return rendered{}, fmt.Errorf("field %q: %w", field, ErrMissingField)
+		}
+		return sub, nil
+	}
+
+	compiled, found := r.compiled[re]
+	if !found {
+		var err error
+		if compiled, err = regexp.Compile(`^(?:` + re.Sub[0].String() + `)$`); err != nil {
+			return rendered{}, err
+		}
+		r.compiled[re] = compiled
+	}
+	if !compiled.MatchString(value) {
+		return rendered{}, fmt.Errorf("value %q of field %q does not match %s: %w", value, field, re.Sub[0], ErrRenderFailure)
+	}
+
+	return rendered{text: value, used: map[string]bool{field: true}}, nil
+}
+
+// renderOptional renders element only when it contains provided fields.
+func (r *renderer) renderOptional(re *syntax.Regexp) rendered {
+	sub, err := r.render(re)
+	if err != nil || len(sub.used) == 0 {
+		return rendered{}
+	}
+	return sub
+}
+
+// renderAlternate renders the branch containing most of provided fields, the first one on a tie.
+func (r *renderer) renderAlternate(branches []*syntax.Regexp) (rendered, error) {
+	var best rendered
+	var firstErr error
+	found := false
+
+	for _, branch := range branches {
+		sub, err := r.render(branch)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !found || len(sub.used) > len(best.used) {
+			best, found = sub, true
+		}
+	}
+
+	if !found {
+		return rendered{}, firstErr
+	}
+	return best, nil
+}
+
+// classRune returns a representative rune of character class given as pairs of ranges,
+// space or a printable ASCII character when the class contains one.
+func classRune(ranges []rune) rune {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] <= ' ' && ' ' <= ranges[i+1] {
+			return ' '
+		}
+	}
+	for _, preferred := range []rune{'a', 'A', '0'} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= preferred && preferred <= ranges[i+1] {
+				return preferred
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i+1] > ' ' && ranges[i] <= '~' {
+			if ranges[i] > ' ' {
+				return ranges[i]
+			}
+			return '!'
+		}
+	}
+	if len(ranges) == 0 {
+		return 'x'
+	}
+	return ranges[0]
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,103 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestRender(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+	require.NoError(t, g.Compile("%{HTTPD_COMMONLOG}", true))
+
+	fields := map[string]interface{}{
+		"source.address":            "127.0.0.1",
+		"user.name":                 "frank",
+		"timestamp":                 "10/Oct/2000:13:55:36 -0700",
+		"http.request.method":       "GET",
+		"url.original":              "/apache_pb.gif",
+		"http.version":              "1.0",
+		"http.response.status_code": 200,
+		"http.response.body.size":   2326,
+	}
+
+	line, err := g.Render(fields)
+	require.NoError(t, err)
+	require.Equal(t, `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`, line)
+
+	// optional body size is rendered by the alternative branch
+	delete(fields, "http.response.body.size")
+	line, err = g.Render(fields)
+	require.NoError(t, err)
+	require.Equal(t, `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 -`, line)
+
+	// request without method and version renders raw request branch
+	line, err = g.Render(map[string]interface{}{
+		"source.address":            "127.0.0.1",
+		"user.name":                 "frank",
+		"timestamp":                 "10/Oct/2000:13:55:36 -0700",
+		"http.response.status_code": 400,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "" 400 -`, line)
+}
+
+func TestRender_Errors(t *testing.T) {
+	g, err := grok.NewWithPatterns(map[string]string{
+		"ADDRESS": `%{WORD:host}:%{INT:port}`,
+	})
+	require.NoError(t, err)
+
+	_, err = g.Render(nil)
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+
+	require.NoError(t, g.Compile(`%{ADDRESS:address}\s+user=%{WORD:user}(?: took %{NUMBER:took}ms)?`, true, grok.WithRenames(map[string]string{"user": "user.name"})))
+
+	testCases := []struct {
+		Name     string
+		Fields   map[string]interface{}
+		Opts     []grok.RenderOption
+		Expected string
+		Err      error
+	}{
+		{"enclosing field from nested", map[string]interface{}{"host": "db", "port": 5432, "user.name": "bob"}, nil, "db:5432 user=bob", nil},
+		{"enclosing field", map[string]interface{}{"address": "db:5432", "user.name": "bob", "took": 1.5}, nil, "db:5432 user=bob took 1.5ms", nil},
+		{"defaults", map[string]interface{}{"address": "db:5432"}, []grok.RenderOption{grok.WithDefaults(map[string]interface{}{"user.name": "nobody"})}, "db:5432 user=nobody", nil},
+		{"missing field", map[string]interface{}{"address": "db:5432"}, nil, "", grok.ErrMissingField},
+		{"value not matching", map[string]interface{}{"address": "db:5432", "user.name": "bob smith"}, nil, "", grok.ErrRenderFailure},
+		{"enclosing and nested disagree", map[string]interface{}{"address": "db:5432", "host": "web", "user.name": "bob"}, nil, "", grok.ErrRenderFailure},
+		{"unknown field", map[string]interface{}{"address": "db:5432", "user": "bob"}, nil, "", grok.ErrRenderFailure},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			line, err := g.Render(tt.Fields, tt.Opts...)
+			if tt.Err != nil {
+				require.ErrorIs(t, err, tt.Err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, line)
+		})
+	}
+}
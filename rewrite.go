@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NoMatchPolicy decides result of Rewrite when text does not match expression.
+type NoMatchPolicy int
+
+const (
+	// NoMatchError returns ErrParseFailure.
+	NoMatchError NoMatchPolicy = iota
+	// NoMatchOriginal returns text unchanged.
+	NoMatchOriginal
+	// NoMatchEmpty returns an empty string.
+	NoMatchEmpty
+)
+
+// RewriteOption customizes rewriting of a line.
+type RewriteOption func(*rewriteConfig)
+
+type rewriteConfig struct {
+	noMatch NoMatchPolicy
+}
+
+// WithNoMatch sets policy applied to text not matching expression, NoMatchError by default.
+func WithNoMatch(policy NoMatchPolicy) RewriteOption {
+	return func(cfg *rewriteConfig) {
+		cfg.noMatch = policy
+	}
+}
+
+// Rewrite formats text matching compiled expression using template referencing captured fields,
+// e.g. "{{timestamp}} {{log.level}} {{message}}".
+// Values are converted according to type hints and may be formatted with a fmt verb, e.g. "{{duration:%.2f}}".
+// Fields not captured from text, e.g. optional ones, are rendered as empty strings.
+// Referencing a field not present in expression or a malformed template results in an error.
+func (grok *Grok) Rewrite(text, template string, opts ...RewriteOption) (string, error) {
+	if grok == nil || grok.re == nil {
+		return "", ErrNotCompiled
+	}
+
+	var cfg rewriteConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	parts, err := parseTemplate(template)
+	if err != nil {
+		return "", err
+	}
+
+	captured := make(map[string]bool)
+	for _, name := range grok.re.SubexpNames() {
+		if name != "" {
+			captured[fieldName(name)] = true
+		}
+	}
+	for _, part := range parts {
+		if part.field != "" && !captured[part.field] {
+			return "", fmt.Errorf("template references field %q not captured by expression", part.field)
+		}
+	}
+
+	// text is submatched once, nil submatches mean no match
+	var matches []string
+	if grok.mayMatchString(text) {
+		matches = grok.re.FindStringSubmatch(text)
+	}
+	if matches == nil {
+		switch cfg.noMatch {
+		case NoMatchOriginal:
+			return text, nil
+		case NoMatchEmpty:
+			return "", nil
+		default:
+			return "", fmt.Errorf("text does not match expression: %w", ErrParseFailure)
+		}
+	}
+
+	values, err := capturesOf(grok.re, matches, grok.convertMatch)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, part := range parts {
+		if part.field == "" {
+			sb.WriteString(part.literal)
+			continue
+		}
+
+		value, found := values[part.field]
+		switch {
+		case !found:
+		case part.format != "":
+			fmt.Fprintf(&sb, part.format, value)
+		default:
+			sb.WriteString(formatValue(value))
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// templatePart is either literal text or a reference to a field with optional format.
+type templatePart struct {
+	literal string
+	field   string
+	format  string
+}
+
+func parseTemplate(template string) ([]templatePart, error) {
+	var parts []templatePart
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("template %q: unclosed reference at offset %d", template, len(template)-len(rest)+start)
+		}
+		end += start
+
+		if start > 0 {
+			parts = append(parts, templatePart{literal: rest[:start]})
+		}
+
+		field, format, _ := strings.Cut(strings.TrimSpace(rest[start+2:end]), ":")
+		if field == "" {
+			return nil, fmt.Errorf("template %q: empty reference at offset %d", template, len(template)-len(rest)+start)
+		}
+		if format != "" && !strings.HasPrefix(format, "%") {
+			return nil, fmt.Errorf("template %q: format %q of field %q is not a fmt verb", template, format, field)
+		}
+		parts = append(parts, templatePart{field: field, format: format})
+
+		rest = rest[end+2:]
+	}
+	if rest != "" {
+		parts = append(parts, templatePart{literal: rest})
+	}
+
+	return parts, nil
+}
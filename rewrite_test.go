@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestRewrite(t *testing.T) {
+	g := grok.New()
+	require.NoError(t, g.AddPatterns(map[string]string{
+		"TIME":     `\d{2}:\d{2}:\d{2}`,
+		"LEVEL":    `[A-Z]+`,
+		"NUMBER":   `\d+(?:\.\d+)?`,
+		"ANYTHING": `.*`,
+	}))
+	require.NoError(t, g.Compile(`\[%{TIME:timestamp}\] %{LEVEL:log.level}(?: took %{NUMBER:duration:float}ms)?(?: code=%{NUMBER:code:int})?: %{ANYTHING:message}`, true))
+
+	const line = `[12:30:45] WARN took 12.5ms code=7: disk almost full`
+
+	testCases := []struct {
+		Name     string
+		Template string
+		Expected string
+	}{
+		{"fields", "{{timestamp}} {{log.level}} {{message}}", "12:30:45 WARN disk almost full"},
+		{"repeated field", "{{log.level}}/{{log.level}}", "WARN/WARN"},
+		{"literal only", "constant", "constant"},
+		{"typed format", "{{duration:%.3f}} {{code:%03d}}", "12.500 007"},
+		{"typed default format", "{{duration}} {{code}}", "12.5 7"},
+		{"spaces in reference", "{{ message }}", "disk almost full"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rewritten, err := g.Rewrite(line, tc.Template)
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, rewritten)
+		})
+	}
+
+	t.Run("optional field not captured", func(t *testing.T) {
+		rewritten, err := g.Rewrite(`[12:30:45] INFO: started`, "{{log.level}} [{{duration}}] {{message}}")
+		require.NoError(t, err)
+		require.Equal(t, "INFO [] started", rewritten)
+	})
+}
+
+func TestRewriteNoMatch(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+	require.NoError(t, g.Compile("%{IP:source.ip} %{WORD:user.name}", true))
+
+	const text = "not matching"
+
+	_, err = g.Rewrite(text, "{{user.name}}")
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+
+	_, err = g.Rewrite(text, "{{user.name}}", grok.WithNoMatch(grok.NoMatchError))
+	require.ErrorIs(t, err, grok.ErrParseFailure)
+
+	rewritten, err := g.Rewrite(text, "{{user.name}}", grok.WithNoMatch(grok.NoMatchOriginal))
+	require.NoError(t, err)
+	require.Equal(t, text, rewritten)
+
+	rewritten, err = g.Rewrite(text, "{{user.name}}", grok.WithNoMatch(grok.NoMatchEmpty))
+	require.NoError(t, err)
+	require.Equal(t, "", rewritten)
+}
+
+func TestRewriteErrors(t *testing.T) {
+	g, err := grok.NewComplete()
+	require.NoError(t, err)
+
+	_, err = g.Rewrite("text", "{{message}}")
+	require.ErrorIs(t, err, grok.ErrNotCompiled)
+
+	require.NoError(t, g.Compile("%{IP:source.ip} %{WORD:user.name}", true))
+
+	for _, template := range []string{
+		"{{user.name",
+		"{{}}",
+		"{{user.name:05d}}",
+		"{{unknown}}",
+	} {
+		t.Run(template, func(t *testing.T) {
+			_, err := g.Rewrite("127.0.0.1 frank", template, grok.WithNoMatch(grok.NoMatchOriginal))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok
+
+import "sort"
+
+// Span is a part of text captured into a field.
+type Span struct {
+	Field string
+	// Start and End are byte offsets of captured text, text[Start:End] is the captured value.
+	Start int
+	End   int
+}
+
+// Spans returns parts of text captured into fields by compiled expression ordered by start offset,
+// enclosing spans precede spans they contain. Fields capturing empty text are omitted.
+// When expression is not a match nil is returned.
+func (grok *Grok) Spans(text string) []Span {
+	if grok == nil || grok.re == nil {
+		return nil
+	}
+
+	loc := grok.re.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return nil
+	}
+
+	spans := []Span{}
+	for i, name := range grok.re.SubexpNames() {
+		start, end := loc[2*i], loc[2*i+1]
+		if name == "" || start < 0 || start == end {
+			continue
+		}
+		spans = append(spans, Span{Field: fieldName(name), Start: start, End: end})
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+		return spans[i].End > spans[j].End
+	})
+
+	return spans
+}
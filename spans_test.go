@@ -0,0 +1,48 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grok_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-grok"
+)
+
+func TestSpans(t *testing.T) {
+	g, err := grok.NewWithPatterns(map[string]string{
+		"ADDRESS": `%{WORD:host}:%{INT:port}`,
+	})
+	require.NoError(t, err)
+	require.Nil(t, g.Spans("anything"))
+
+	require.NoError(t, g.Compile(`%{WORD:user.name}@%{ADDRESS:address}(?: %{WORD:comment})?`, true))
+
+	text := "bob@example:8080"
+	spans := g.Spans(text)
+	require.Equal(t, []grok.Span{
+		{Field: "user.name", Start: 0, End: 3},
+		{Field: "address", Start: 4, End: 16},
+		{Field: "host", Start: 4, End: 11},
+		{Field: "port", Start: 12, End: 16},
+	}, spans)
+	require.Equal(t, "example", text[spans[2].Start:spans[2].End])
+
+	require.Nil(t, g.Spans("not matching"))
+}